@@ -0,0 +1,278 @@
+// Package api contains REST clients for the forges hosting a repository.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxRetries is how often a rate limited request is retried
+const DefaultMaxRetries = 3
+
+// maxRetryWait caps how long a single rate limit wait may take
+const maxRetryWait = time.Minute
+
+// Error is returned for responses with a non-2xx status code
+type Error struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// Option configures a client
+type Option func(*client)
+
+// WithHTTPClient replaces the http.Client used for requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = hc
+	}
+}
+
+// WithBaseURL replaces the API root, e.g. an httptest server URL
+func WithBaseURL(baseURL string) Option {
+	return func(c *client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithMaxRetries sets how often rate limited requests are retried
+func WithMaxRetries(n int) Option {
+	return func(c *client) {
+		c.maxRetries = n
+	}
+}
+
+// client is the REST plumbing shared by the forge clients
+type client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	// authorize adds credentials and forge specific headers to a request
+	authorize func(*http.Request)
+	// nextPage returns the URL of the next page, or "" on the last page
+	nextPage func(*http.Response) string
+	// sleep waits between retries, replaced to avoid waiting in tests
+	sleep func(context.Context, time.Duration) error
+}
+
+func newClient(baseURL string, opts []Option) *client {
+	c := &client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		maxRetries: DefaultMaxRetries,
+		nextPage:   linkNextPage,
+		sleep:      sleepContext,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// url joins the path to the base URL unless it is already absolute
+func (c *client) url(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return c.baseURL + "/" + strings.TrimPrefix(path, "/")
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out, retrying while the forge reports a rate limit
+func (c *client) do(ctx context.Context, method, path string, body, out any) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encoding request body: %w", err)
+		}
+	}
+
+	return c.send(ctx, method, path, "application/json", payload, out)
+}
+
+// send performs the request, the payload is resent on every retry
+func (c *client) send(ctx context.Context, method, path, contentType string, payload []byte, out any) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.url(path), bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if c.authorize != nil {
+			c.authorize(req)
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			slog.Debug("http", "method", method, "url", req.URL.Redacted(), "duration", time.Since(start), "error", err)
+			return nil, err
+		}
+		slog.Debug("http", "method", method, "url", req.URL.Redacted(), "duration", time.Since(start), "status", resp.StatusCode)
+
+		if wait, ok := retryAfter(method, resp); ok && attempt < c.maxRetries {
+			resp.Body.Close()
+			if err := c.sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp, responseError(req, resp)
+		}
+
+		if out != nil && resp.StatusCode != http.StatusNoContent {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return resp, fmt.Errorf("decoding response of %s %s: %w", method, req.URL, err)
+			}
+		}
+		return resp, nil
+	}
+}
+
+// getAll follows pagination and appends every page of results
+func getAll[T any](ctx context.Context, c *client, path string) ([]T, error) {
+	var all []T
+	for path != "" {
+		var page []T
+		resp, err := c.do(ctx, http.MethodGet, path, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		path = c.nextPage(resp)
+	}
+	return all, nil
+}
+
+// retryAfter reports whether the response is a rate limit and how long to
+// wait before retrying. A rate limited request was refused before anything
+// happened, while a POST answered with 503 may have been processed, e.g.
+// creating a release, and is not sent again.
+func retryAfter(method string, resp *http.Response) (time.Duration, bool) {
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusServiceUnavailable && method != http.MethodPost) ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0")
+	if !limited {
+		return 0, false
+	}
+
+	wait := time.Second
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil {
+			wait = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(s); err == nil {
+			wait = time.Until(t)
+		}
+	} else if reset := firstHeader(resp.Header, "RateLimit-Reset", "X-RateLimit-Reset"); reset != "" {
+		if epoch, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			wait = time.Until(time.Unix(epoch, 0))
+		}
+	}
+
+	return min(max(wait, 0), maxRetryWait), true
+}
+
+func responseError(req *http.Request, resp *http.Response) error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var parsed struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &parsed) == nil {
+		switch m := parsed.Message.(type) {
+		case string:
+			apiErr.Message = m
+		case nil:
+			apiErr.Message = parsed.Error
+		default:
+			// gitlab reports validation errors as an object
+			encoded, _ := json.Marshal(m)
+			apiErr.Message = string(encoded)
+		}
+	} else {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+
+	return apiErr
+}
+
+// linkNextPage reads the "next" relation of an RFC 8288 Link header
+func linkNextPage(resp *http.Response) string {
+	for link := range strings.SplitSeq(resp.Header.Get("Link"), ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		target := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return target
+			}
+		}
+	}
+	return ""
+}
+
+func firstHeader(h http.Header, keys ...string) string {
+	for _, k := range keys {
+		if v := h.Get(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// withQuery appends query parameters to a path
+func withQuery(path string, q url.Values) string {
+	if len(q) == 0 {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + q.Encode()
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// ErrUnsupportedForge indicates that no API client exists for a provider
+var ErrUnsupportedForge = errors.New("unsupported forge")
+
+// Forge is the part of a forge API used by cliborg, the project is the
+// full path of the repository, e.g. "owner/name"
+type Forge interface {
+	CreateRelease(ctx context.Context, project string, input ReleaseInput) (*Release, error)
+	MergedMergeRequestsSinceTag(ctx context.Context, project, tag, targetBranch string) ([]MergeRequest, error)
+	Repository(ctx context.Context, project string) (*Repository, error)
+}
+
+// AssetUploader is implemented by forges that store files on a release
+type AssetUploader interface {
+	UploadReleaseAsset(ctx context.Context, project string, release *Release, path string) error
+}
+
+var (
+	_ Forge         = (*GitLabClient)(nil)
+	_ Forge         = (*GitHubClient)(nil)
+	_ AssetUploader = (*GitHubClient)(nil)
+)
+
+// NewForge returns the client for the provider hosting the project
+func NewForge(project *git.Project, token string, opts ...Option) (Forge, error) {
+	host := project.Scheme + "://" + project.Host
+
+	switch project.Provider {
+	case git.ProviderGitLab:
+		return NewGitLabClient(host, token, opts...), nil
+	case git.ProviderGitHub:
+		return NewGitHubClient(host, token, opts...), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedForge, project.Host)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+func TestNewForge(t *testing.T) {
+	tests := []struct {
+		name    string
+		project git.Project
+		want    string
+		wantErr error
+	}{
+		{name: "github", project: git.Project{Provider: git.ProviderGitHub, Scheme: "https", Host: "github.com", Path: "o/n"}, want: "https://api.github.com"},
+		{name: "github enterprise", project: git.Project{Provider: git.ProviderGitHub, Scheme: "https", Host: "github.example.com", Path: "o/n"}, want: "https://github.example.com/api/v3"},
+		{name: "gitlab", project: git.Project{Provider: git.ProviderGitLab, Scheme: "https", Host: "gitlab.com", Path: "g/n"}, want: "https://gitlab.com/api/v4"},
+		{name: "gitlab over http", project: git.Project{Provider: git.ProviderGitLab, Scheme: "http", Host: "gitlab.local:8080", Path: "g/n"}, want: "http://gitlab.local:8080/api/v4"},
+		{name: "gitea", project: git.Project{Provider: git.ProviderGitea, Scheme: "https", Host: "codeberg.org", Path: "o/n"}, wantErr: ErrUnsupportedForge},
+		{name: "unknown", project: git.Project{Scheme: "https", Host: "git.example.com", Path: "o/n"}, wantErr: ErrUnsupportedForge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forge, err := NewForge(&tt.project, "token")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var baseURL string
+			switch f := forge.(type) {
+			case *GitHubClient:
+				if tt.project.Provider != git.ProviderGitHub {
+					t.Errorf("got a GitHub client for %s", tt.project.Provider)
+				}
+				baseURL = f.baseURL
+			case *GitLabClient:
+				if tt.project.Provider != git.ProviderGitLab {
+					t.Errorf("got a GitLab client for %s", tt.project.Provider)
+				}
+				baseURL = f.baseURL
+			}
+			if baseURL != tt.want {
+				t.Errorf("base URL = %q, want %q", baseURL, tt.want)
+			}
+		})
+	}
+}
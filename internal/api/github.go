@@ -0,0 +1,258 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitHubHost is the public GitHub instance, which serves its API from
+// separate hosts
+const gitHubHost = "github.com"
+
+// GitHubClient talks to the GitHub REST API of github.com or a GitHub
+// Enterprise Server instance
+type GitHubClient struct {
+	*client
+	uploadURL string
+}
+
+// NewGitHubClient returns a client for the GitHub instance on host. The host
+// may include a scheme for instances not served over https.
+func NewGitHubClient(host, token string, opts ...Option) *GitHubClient {
+	apiURL, uploadURL := gitHubURLs(host)
+	c := newClient(apiURL, opts)
+	c.authorize = func(req *http.Request) {
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	// uploads go to the API root when it was replaced, e.g. by a test server
+	if c.baseURL != apiURL {
+		uploadURL = c.baseURL
+	}
+
+	return &GitHubClient{client: c, uploadURL: uploadURL}
+}
+
+type gitHubRelease struct {
+	ID      int64  `json:"id"`
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+type gitHubPullRequest struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	HTMLURL        string     `json:"html_url"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+	MergedAt       *time.Time `json:"merged_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	User           struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+type gitHubRepository struct {
+	FullName      string            `json:"full_name"`
+	DefaultBranch string            `json:"default_branch"`
+	CloneURL      string            `json:"clone_url"`
+	SSHURL        string            `json:"ssh_url"`
+	Parent        *gitHubRepository `json:"parent"`
+}
+
+func (r *gitHubRepository) toRepository() *Repository {
+	if r == nil {
+		return nil
+	}
+	return &Repository{
+		Path:          r.FullName,
+		DefaultBranch: r.DefaultBranch,
+		HTTPURL:       r.CloneURL,
+		SSHURL:        r.SSHURL,
+		Parent:        r.Parent.toRepository(),
+	}
+}
+
+type gitHubCommit struct {
+	Commit struct {
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// CreateRelease publishes a release for a tag of the repository
+func (c *GitHubClient) CreateRelease(ctx context.Context, repo string, input ReleaseInput) (*Release, error) {
+	body := map[string]string{
+		"tag_name": input.TagName,
+		"name":     input.Name,
+		"body":     input.Description,
+	}
+	if input.Ref != "" {
+		body["target_commitish"] = input.Ref
+	}
+
+	var created gitHubRelease
+	_, err := c.do(ctx, http.MethodPost, repoPath(repo)+"/releases", body, &created)
+	if err != nil {
+		return nil, fmt.Errorf("creating release %s: %w", input.TagName, err)
+	}
+
+	return &Release{
+		ID:          created.ID,
+		TagName:     created.TagName,
+		Name:        created.Name,
+		Description: created.Body,
+		URL:         created.HTMLURL,
+	}, nil
+}
+
+// UploadReleaseAsset attaches the file at path to a published release
+func (c *GitHubClient) UploadReleaseAsset(ctx context.Context, repo string, release *Release, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading release asset: %w", err)
+	}
+
+	name := filepath.Base(path)
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	q := url.Values{}
+	q.Set("name", name)
+	assetURL := fmt.Sprintf("%s/%s/releases/%d/assets", c.uploadURL, repoPath(repo), release.ID)
+
+	_, err = c.send(ctx, http.MethodPost, withQuery(assetURL, q), contentType, data, nil)
+	if err != nil {
+		return fmt.Errorf("uploading release asset %s: %w", name, err)
+	}
+	return nil
+}
+
+// Repository returns a repository and the one it was forked from
+func (c *GitHubClient) Repository(ctx context.Context, repo string) (*Repository, error) {
+	var r gitHubRepository
+	_, err := c.do(ctx, http.MethodGet, repoPath(repo), nil, &r)
+	if err != nil {
+		return nil, fmt.Errorf("getting repository %s: %w", repo, err)
+	}
+	return r.toRepository(), nil
+}
+
+// TagDate returns the commit date of a tag of the repository
+func (c *GitHubClient) TagDate(ctx context.Context, repo, tag string) (time.Time, error) {
+	var commit gitHubCommit
+	_, err := c.do(ctx, http.MethodGet, repoPath(repo)+"/commits/"+url.PathEscape(tag), nil, &commit)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("getting tag %s: %w", tag, err)
+	}
+	return commit.Commit.Committer.Date, nil
+}
+
+// MergedPullRequests lists the pull requests merged after since, oldest
+// first. An empty base includes every target branch.
+func (c *GitHubClient) MergedPullRequests(ctx context.Context, repo string, since time.Time, base string) ([]MergeRequest, error) {
+	q := url.Values{}
+	q.Set("state", "closed")
+	q.Set("sort", "updated")
+	q.Set("direction", "desc")
+	q.Set("per_page", "100")
+	if base != "" {
+		q.Set("base", base)
+	}
+
+	var merged []MergeRequest
+	path := withQuery(repoPath(repo)+"/pulls", q)
+	for path != "" {
+		var page []gitHubPullRequest
+		resp, err := c.do(ctx, http.MethodGet, path, nil, &page)
+		if err != nil {
+			return nil, fmt.Errorf("listing merged pull requests: %w", err)
+		}
+
+		for _, pr := range page {
+			if pr.MergedAt == nil || !pr.MergedAt.After(since) {
+				continue
+			}
+			labels := make([]string, 0, len(pr.Labels))
+			for _, l := range pr.Labels {
+				labels = append(labels, l.Name)
+			}
+			merged = append(merged, MergeRequest{
+				Number:         pr.Number,
+				Title:          pr.Title,
+				Body:           pr.Body,
+				URL:            pr.HTMLURL,
+				Author:         pr.User.Login,
+				Labels:         labels,
+				TargetBranch:   pr.Base.Ref,
+				MergeCommitSHA: pr.MergeCommitSHA,
+				MergedAt:       *pr.MergedAt,
+			})
+		}
+
+		// results are sorted by update time, merging updates a pull request
+		// so nothing older can have been merged after since
+		if len(page) > 0 && page[len(page)-1].UpdatedAt.Before(since) {
+			break
+		}
+		path = c.nextPage(resp)
+	}
+	sortByMergedAt(merged)
+
+	return merged, nil
+}
+
+// MergedPullRequestsSinceTag lists the pull requests merged after the tagged
+// commit. An empty tag lists every merged pull request.
+func (c *GitHubClient) MergedPullRequestsSinceTag(ctx context.Context, repo, tag, base string) ([]MergeRequest, error) {
+	var since time.Time
+	if tag != "" {
+		var err error
+		since, err = c.TagDate(ctx, repo, tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.MergedPullRequests(ctx, repo, since, base)
+}
+
+// MergedMergeRequestsSinceTag implements Forge
+func (c *GitHubClient) MergedMergeRequestsSinceTag(ctx context.Context, repo, tag, base string) ([]MergeRequest, error) {
+	return c.MergedPullRequestsSinceTag(ctx, repo, tag, base)
+}
+
+func repoPath(repo string) string {
+	owner, name, _ := strings.Cut(repo, "/")
+	return "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+}
+
+// gitHubURLs returns the API and upload roots for a GitHub host
+func gitHubURLs(host string) (string, string) {
+	base := instanceURL(host)
+	if u, err := url.Parse(base); err == nil && strings.EqualFold(u.Hostname(), gitHubHost) {
+		return "https://api.github.com", "https://uploads.github.com"
+	}
+	return base + "/api/v3", base + "/api/uploads"
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestGitHub(t *testing.T, handler http.HandlerFunc) (*GitHubClient, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewGitHubClient(srv.URL, "secret", WithBaseURL(srv.URL)), srv
+}
+
+func TestGitHubCreateRelease(t *testing.T) {
+	c, _ := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/name/releases" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("Accept"); got != "application/vnd.github+json" {
+			t.Errorf("Accept = %q", got)
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		want := map[string]string{"tag_name": "v1.2.0", "name": "v1.2.0", "body": "notes", "target_commitish": "main"}
+		for k, v := range want {
+			if body[k] != v {
+				t.Errorf("body[%q] = %q, want %q", k, body[k], v)
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": 42, "tag_name": "v1.2.0", "name": "v1.2.0", "body": "notes",
+			"html_url": "https://github.com/owner/name/releases/tag/v1.2.0"}`)
+	})
+
+	release, err := c.CreateRelease(context.Background(), "owner/name", ReleaseInput{
+		TagName:     "v1.2.0",
+		Name:        "v1.2.0",
+		Description: "notes",
+		Ref:         "main",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.ID != 42 || release.URL != "https://github.com/owner/name/releases/tag/v1.2.0" {
+		t.Errorf("CreateRelease() = %+v", release)
+	}
+}
+
+func TestGitHubCreateReleaseError(t *testing.T) {
+	c, _ := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message": "Validation Failed"}`)
+	})
+
+	_, err := c.CreateRelease(context.Background(), "owner/name", ReleaseInput{TagName: "v1.2.0"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Message != "Validation Failed" {
+		t.Errorf("err = %v, want a 422 *Error", err)
+	}
+}
+
+func TestGitHubUploadReleaseAsset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cliborg linux.tar.gz")
+	if err := os.WriteFile(path, []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/name/releases/42/assets" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("name"); got != "cliborg linux.tar.gz" {
+			t.Errorf("name = %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/gzip" && got != "application/x-gzip" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		data, _ := io.ReadAll(r.Body)
+		if string(data) != "archive" {
+			t.Errorf("body = %q", data)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := c.UploadReleaseAsset(context.Background(), "owner/name", &Release{ID: 42}, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitHubURLs(t *testing.T) {
+	tests := []struct {
+		host       string
+		wantAPI    string
+		wantUpload string
+	}{
+		{"github.com", "https://api.github.com", "https://uploads.github.com"},
+		{"https://GitHub.com/", "https://api.github.com", "https://uploads.github.com"},
+		{"github.example.com", "https://github.example.com/api/v3", "https://github.example.com/api/uploads"},
+		{"http://github.local:8080", "http://github.local:8080/api/v3", "http://github.local:8080/api/uploads"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			api, upload := gitHubURLs(tt.host)
+			if api != tt.wantAPI || upload != tt.wantUpload {
+				t.Errorf("gitHubURLs(%q) = %q, %q, want %q, %q", tt.host, api, upload, tt.wantAPI, tt.wantUpload)
+			}
+		})
+	}
+
+	c := NewGitHubClient("github.com", "")
+	if c.uploadURL != "https://uploads.github.com" {
+		t.Errorf("uploadURL = %q", c.uploadURL)
+	}
+}
+
+func TestGitHubMergedPullRequests(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var srvURL string
+	pages := map[string]string{
+		"": `[
+			{"number": 3, "title": "feat: c", "merge_commit_sha": "ccc", "merged_at": "2025-01-03T00:00:00Z",
+			 "updated_at": "2025-01-03T00:00:00Z", "labels": [{"name": "enhancement"}], "user": {"login": "dev"}, "base": {"ref": "main"}},
+			{"number": 5, "title": "closed", "merged_at": null, "updated_at": "2025-01-02T12:00:00Z"}
+		]`,
+		"2": `[
+			{"number": 2, "title": "fix: b", "merge_commit_sha": "bbb", "merged_at": "2025-01-02T00:00:00Z", "updated_at": "2025-01-02T00:00:00Z"},
+			{"number": 1, "title": "fix: a", "merge_commit_sha": "aaa", "merged_at": "2024-12-30T00:00:00Z", "updated_at": "2024-12-30T00:00:00Z"}
+		]`,
+	}
+	var requested []string
+	c, srv := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != "closed" || q.Get("sort") != "updated" || q.Get("direction") != "desc" || q.Get("base") != "main" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		page := q.Get("page")
+		requested = append(requested, page)
+		// every page links to another, the update times end the listing
+		next := "2"
+		if page == "2" {
+			next = "3"
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/name/pulls?state=closed&sort=updated&direction=desc&base=main&page=%s>; rel="next", <%s/last>; rel="last"`, srvURL, next, srvURL))
+		fmt.Fprint(w, pages[page])
+	})
+	srvURL = srv.URL
+
+	prs, err := c.MergedPullRequests(context.Background(), "owner/name", since, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(requested, []string{"", "2"}) {
+		t.Errorf("requested pages %q, want the first two only", requested)
+	}
+
+	var got []string
+	for _, pr := range prs {
+		got = append(got, fmt.Sprintf("#%d %s", pr.Number, pr.MergeCommitSHA))
+	}
+	want := []string{"#2 bbb", "#3 ccc"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("MergedPullRequests() = %v, want %v", got, want)
+	}
+	if prs[1].Author != "dev" || prs[1].TargetBranch != "main" || !slices.Equal(prs[1].Labels, []string{"enhancement"}) {
+		t.Errorf("MergedPullRequests() = %+v", prs[1])
+	}
+}
+
+func TestLinkNextPage(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{`<https://api.github.com/x?page=2>; rel="next", <https://api.github.com/x?page=5>; rel="last"`, "https://api.github.com/x?page=2"},
+		{`<https://api.github.com/x?page=1>; rel="prev"`, ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{"Link": {tt.link}}}
+		if got := linkNextPage(resp); got != tt.want {
+			t.Errorf("linkNextPage(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
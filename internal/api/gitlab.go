@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitLabClient talks to the GitLab REST API v4 of gitlab.com or a
+// self-hosted instance
+type GitLabClient struct {
+	*client
+}
+
+// NewGitLabClient returns a client for the GitLab instance on host. The host
+// may include a scheme for instances not served over https.
+func NewGitLabClient(host, token string, opts ...Option) *GitLabClient {
+	c := newClient(instanceURL(host)+"/api/v4", opts)
+	c.authorize = func(req *http.Request) {
+		if token != "" {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+	}
+	c.nextPage = gitLabNextPage
+	return &GitLabClient{c}
+}
+
+type gitLabRelease struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+type gitLabMergeRequest struct {
+	IID            int        `json:"iid"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	WebURL         string     `json:"web_url"`
+	Labels         []string   `json:"labels"`
+	TargetBranch   string     `json:"target_branch"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+	SquashSHA      string     `json:"squash_commit_sha"`
+	SHA            string     `json:"sha"`
+	MergedAt       *time.Time `json:"merged_at"`
+	Author         struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+type gitLabProject struct {
+	PathWithNamespace string         `json:"path_with_namespace"`
+	DefaultBranch     string         `json:"default_branch"`
+	HTTPURLToRepo     string         `json:"http_url_to_repo"`
+	SSHURLToRepo      string         `json:"ssh_url_to_repo"`
+	ForkedFromProject *gitLabProject `json:"forked_from_project"`
+}
+
+func (p *gitLabProject) toRepository() *Repository {
+	if p == nil {
+		return nil
+	}
+	return &Repository{
+		Path:          p.PathWithNamespace,
+		DefaultBranch: p.DefaultBranch,
+		HTTPURL:       p.HTTPURLToRepo,
+		SSHURL:        p.SSHURLToRepo,
+		Parent:        p.ForkedFromProject.toRepository(),
+	}
+}
+
+type gitLabTag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID            string    `json:"id"`
+		CommittedDate time.Time `json:"committed_date"`
+	} `json:"commit"`
+}
+
+// CreateRelease publishes a release for a tag of the project
+func (c *GitLabClient) CreateRelease(ctx context.Context, project string, input ReleaseInput) (*Release, error) {
+	body := map[string]string{
+		"tag_name":    input.TagName,
+		"name":        input.Name,
+		"description": input.Description,
+	}
+	if input.Ref != "" {
+		body["ref"] = input.Ref
+	}
+
+	var created gitLabRelease
+	_, err := c.do(ctx, http.MethodPost, projectPath(project)+"/releases", body, &created)
+	if err != nil {
+		return nil, fmt.Errorf("creating release %s: %w", input.TagName, err)
+	}
+
+	return &Release{
+		TagName:     created.TagName,
+		Name:        created.Name,
+		Description: created.Description,
+		URL:         created.Links.Self,
+	}, nil
+}
+
+// Repository returns a project and the one it was forked from
+func (c *GitLabClient) Repository(ctx context.Context, project string) (*Repository, error) {
+	var p gitLabProject
+	_, err := c.do(ctx, http.MethodGet, projectPath(project), nil, &p)
+	if err != nil {
+		return nil, fmt.Errorf("getting project %s: %w", project, err)
+	}
+	return p.toRepository(), nil
+}
+
+// TagDate returns the commit date of a tag of the project
+func (c *GitLabClient) TagDate(ctx context.Context, project, tag string) (time.Time, error) {
+	var t gitLabTag
+	_, err := c.do(ctx, http.MethodGet, projectPath(project)+"/repository/tags/"+url.PathEscape(tag), nil, &t)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("getting tag %s: %w", tag, err)
+	}
+	return t.Commit.CommittedDate, nil
+}
+
+// MergedMergeRequests lists the merge requests merged after since, oldest
+// first. An empty targetBranch includes every branch.
+func (c *GitLabClient) MergedMergeRequests(ctx context.Context, project string, since time.Time, targetBranch string) ([]MergeRequest, error) {
+	q := url.Values{}
+	q.Set("state", "merged")
+	q.Set("per_page", "100")
+	q.Set("order_by", "updated_at")
+	q.Set("sort", "asc")
+	if !since.IsZero() {
+		// merging updates the merge request, so this never misses one
+		q.Set("updated_after", since.UTC().Format(time.RFC3339))
+	}
+	if targetBranch != "" {
+		q.Set("target_branch", targetBranch)
+	}
+
+	mrs, err := getAll[gitLabMergeRequest](ctx, c.client, withQuery(projectPath(project)+"/merge_requests", q))
+	if err != nil {
+		return nil, fmt.Errorf("listing merged merge requests: %w", err)
+	}
+
+	var merged []MergeRequest
+	for _, mr := range mrs {
+		if mr.MergedAt == nil || !mr.MergedAt.After(since) {
+			continue
+		}
+		// fast-forward merges have no merge commit, the squash commit or
+		// the head of the merge request is what landed on the target branch
+		sha := mr.MergeCommitSHA
+		if sha == "" {
+			sha = mr.SquashSHA
+		}
+		if sha == "" {
+			sha = mr.SHA
+		}
+		merged = append(merged, MergeRequest{
+			Number:         mr.IID,
+			Title:          mr.Title,
+			Body:           mr.Description,
+			URL:            mr.WebURL,
+			Author:         mr.Author.Username,
+			Labels:         mr.Labels,
+			TargetBranch:   mr.TargetBranch,
+			MergeCommitSHA: sha,
+			MergedAt:       *mr.MergedAt,
+		})
+	}
+	sortByMergedAt(merged)
+
+	return merged, nil
+}
+
+// MergedMergeRequestsSinceTag lists the merge requests merged after the
+// tagged commit. An empty tag lists every merged merge request.
+func (c *GitLabClient) MergedMergeRequestsSinceTag(ctx context.Context, project, tag, targetBranch string) ([]MergeRequest, error) {
+	var since time.Time
+	if tag != "" {
+		var err error
+		since, err = c.TagDate(ctx, project, tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.MergedMergeRequests(ctx, project, since, targetBranch)
+}
+
+// projectPath returns the API path of a project, namespaced paths are
+// encoded as a single segment
+func projectPath(project string) string {
+	return "projects/" + url.PathEscape(project)
+}
+
+// gitLabNextPage uses the X-Next-Page header, falling back to the Link header
+func gitLabNextPage(resp *http.Response) string {
+	next := resp.Header.Get("X-Next-Page")
+	if next == "" {
+		return linkNextPage(resp)
+	}
+	if _, err := strconv.Atoi(next); err != nil || resp.Request == nil {
+		return ""
+	}
+
+	u := *resp.Request.URL
+	q := u.Query()
+	q.Set("page", next)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// instanceURL adds the https scheme to bare host names
+func instanceURL(host string) string {
+	host = strings.TrimSuffix(host, "/")
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return host
+	}
+	return "https://" + host
+}
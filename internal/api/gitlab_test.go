@@ -0,0 +1,276 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestGitLab returns a client for a test server running handler, waits
+// between retries are recorded instead of slept
+func newTestGitLab(t *testing.T, handler http.HandlerFunc) (*GitLabClient, *[]time.Duration) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := NewGitLabClient(srv.URL, "secret", WithBaseURL(srv.URL+"/api/v4"))
+	var waits []time.Duration
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return c, &waits
+}
+
+func TestGitLabPrivateToken(t *testing.T) {
+	c, _ := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+			t.Errorf("PRIVATE-TOKEN = %q, want %q", got, "secret")
+		}
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Fname/repository/tags/v1.0.0" {
+			t.Errorf("path = %s", r.URL.EscapedPath())
+		}
+		fmt.Fprint(w, `{"name": "v1.0.0", "commit": {"committed_date": "2025-01-02T03:04:05Z"}}`)
+	})
+
+	date, err := c.TagDate(context.Background(), "group/sub/name", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !date.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("TagDate() = %v", date)
+	}
+}
+
+func TestGitLabRepository(t *testing.T) {
+	c, _ := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Fname" {
+			t.Errorf("path = %s", r.URL.EscapedPath())
+		}
+		fmt.Fprint(w, `{"path_with_namespace": "group/sub/name", "default_branch": "main",
+			"forked_from_project": {"path_with_namespace": "upstream/name"}}`)
+	})
+
+	repo, err := c.Repository(context.Background(), "group/sub/name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Path != "group/sub/name" || repo.DefaultBranch != "main" {
+		t.Errorf("Repository() = %+v", repo)
+	}
+	if repo.Parent == nil || repo.Parent.Path != "upstream/name" {
+		t.Errorf("Parent = %+v, want upstream/name", repo.Parent)
+	}
+}
+
+func TestGitLabCreateRelease(t *testing.T) {
+	c, _ := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/api/v4/projects/group%2Fname/releases" {
+			t.Errorf("request = %s %s", r.Method, r.URL.EscapedPath())
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		want := map[string]string{"tag_name": "v1.2.0", "name": "v1.2.0", "description": "## Added\n- x", "ref": "abc123"}
+		for k, v := range want {
+			if body[k] != v {
+				t.Errorf("body[%q] = %q, want %q", k, body[k], v)
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"tag_name": "v1.2.0", "name": "v1.2.0", "description": "## Added\n- x",
+			"_links": {"self": "https://gitlab.example.com/group/name/-/releases/v1.2.0"}}`)
+	})
+
+	release, err := c.CreateRelease(context.Background(), "group/name", ReleaseInput{
+		TagName:     "v1.2.0",
+		Name:        "v1.2.0",
+		Description: "## Added\n- x",
+		Ref:         "abc123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.URL != "https://gitlab.example.com/group/name/-/releases/v1.2.0" {
+		t.Errorf("URL = %q", release.URL)
+	}
+}
+
+func TestGitLabRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		header    http.Header
+		method    string
+		wantCalls int
+		wantWaits []time.Duration
+		wantErr   bool
+	}{
+		{
+			name:      "429 with Retry-After",
+			status:    http.StatusTooManyRequests,
+			header:    http.Header{"Retry-After": {"2"}},
+			method:    http.MethodGet,
+			wantCalls: 2,
+			wantWaits: []time.Duration{2 * time.Second},
+		},
+		{
+			name:      "503 on GET",
+			status:    http.StatusServiceUnavailable,
+			header:    http.Header{"Retry-After": {"5"}},
+			method:    http.MethodGet,
+			wantCalls: 2,
+			wantWaits: []time.Duration{5 * time.Second},
+		},
+		{
+			name:      "429 on POST",
+			status:    http.StatusTooManyRequests,
+			header:    http.Header{"Retry-After": {"1"}},
+			method:    http.MethodPost,
+			wantCalls: 2,
+			wantWaits: []time.Duration{time.Second},
+		},
+		{
+			name:      "503 on POST is not retried",
+			status:    http.StatusServiceUnavailable,
+			header:    http.Header{"Retry-After": {"1"}},
+			method:    http.MethodPost,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			c, waits := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					for k, v := range tt.header {
+						w.Header()[k] = v
+					}
+					w.WriteHeader(tt.status)
+					return
+				}
+				fmt.Fprint(w, `{}`)
+			})
+
+			_, err := c.do(context.Background(), tt.method, "projects/1", nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			var apiErr *Error
+			if tt.wantErr && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.status) {
+				t.Errorf("err = %v, want an *Error with status %d", err, tt.status)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if !slices.Equal(*waits, tt.wantWaits) {
+				t.Errorf("waits = %v, want %v", *waits, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestGitLabRetryGivesUp(t *testing.T) {
+	calls := 0
+	c, waits := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := c.do(context.Background(), http.MethodGet, "projects/1", nil, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != DefaultMaxRetries+1 || len(*waits) != DefaultMaxRetries {
+		t.Errorf("calls = %d, waits = %d, want %d and %d", calls, len(*waits), DefaultMaxRetries+1, DefaultMaxRetries)
+	}
+}
+
+func TestGitLabMergedMergeRequests(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pages := map[string]string{
+		"1": `[
+			{"iid": 3, "title": "feat: c", "merge_commit_sha": "ccc", "merged_at": "2025-01-03T00:00:00Z", "labels": ["feature"]},
+			{"iid": 1, "title": "fix: before", "merge_commit_sha": "aaa", "merged_at": "2024-12-31T00:00:00Z"}
+		]`,
+		"2": `[
+			{"iid": 2, "title": "fix: b", "squash_commit_sha": "bbb", "merged_at": "2025-01-02T00:00:00Z", "author": {"username": "dev"}},
+			{"iid": 4, "title": "closed", "merged_at": null},
+			{"iid": 5, "title": "fix: e", "sha": "eee", "merged_at": "2025-01-04T00:00:00Z"}
+		]`,
+	}
+	var requested []string
+	c, _ := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != "merged" || q.Get("target_branch") != "main" || q.Get("updated_after") != "2025-01-01T00:00:00Z" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		page := q.Get("page")
+		if page == "" {
+			page = "1"
+		}
+		requested = append(requested, page)
+		if page == "1" {
+			w.Header().Set("X-Next-Page", "2")
+		} else {
+			w.Header().Set("X-Next-Page", "")
+		}
+		fmt.Fprint(w, pages[page])
+	})
+
+	mrs, err := c.MergedMergeRequests(context.Background(), "group/name", since, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(requested, []string{"1", "2"}) {
+		t.Errorf("requested pages %v, want [1 2]", requested)
+	}
+
+	var got []string
+	for _, mr := range mrs {
+		got = append(got, fmt.Sprintf("!%d %s", mr.Number, mr.MergeCommitSHA))
+	}
+	// sorted by merge date, the squash commit or the head stands in for a
+	// missing merge commit
+	want := []string{"!2 bbb", "!3 ccc", "!5 eee"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("MergedMergeRequests() = %v, want %v", got, want)
+	}
+	if mrs[0].Author != "dev" || !slices.Equal(mrs[1].Labels, []string{"feature"}) {
+		t.Errorf("MergedMergeRequests() = %+v", mrs)
+	}
+}
+
+func TestGitLabNextPage(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://gitlab.example.com/api/v4/projects/1/merge_requests?state=merged&page=1", nil)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{name: "x-next-page", header: http.Header{"X-Next-Page": {"2"}}, want: "https://gitlab.example.com/api/v4/projects/1/merge_requests?page=2&state=merged"},
+		{name: "last page", header: http.Header{}, want: ""},
+		{name: "link header", header: http.Header{"Link": {`<https://gitlab.example.com/next>; rel="next"`}}, want: "https://gitlab.example.com/next"},
+		{name: "invalid page", header: http.Header{"X-Next-Page": {"x"}}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gitLabNextPage(&http.Response{Header: tt.header, Request: req})
+			if got != tt.want {
+				t.Errorf("gitLabNextPage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
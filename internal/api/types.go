@@ -0,0 +1,55 @@
+package api
+
+import (
+	"slices"
+	"time"
+)
+
+// ReleaseInput is the data needed to publish a release for a tag
+type ReleaseInput struct {
+	TagName string
+	Name    string
+	// Description is the markdown body, usually the rendered changelog
+	Description string
+	// Ref is the commit to tag when TagName does not exist yet
+	Ref string
+}
+
+// Release is a published forge release
+type Release struct {
+	// ID is only set by forges that address releases by id
+	ID          int64
+	TagName     string
+	Name        string
+	Description string
+	URL         string
+}
+
+// MergeRequest is a merged merge request or pull request
+type MergeRequest struct {
+	Number         int
+	Title          string
+	Body           string
+	URL            string
+	Author         string
+	Labels         []string
+	TargetBranch   string
+	MergeCommitSHA string
+	MergedAt       time.Time
+}
+
+// Repository is a project on a forge
+type Repository struct {
+	Path          string
+	DefaultBranch string
+	HTTPURL       string
+	SSHURL        string
+	// Parent is the project this one was forked from, nil if it is no fork
+	Parent *Repository
+}
+
+func sortByMergedAt(mrs []MergeRequest) {
+	slices.SortStableFunc(mrs, func(a, b MergeRequest) int {
+		return a.MergedAt.Compare(b.MergedAt)
+	})
+}
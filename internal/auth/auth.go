@@ -0,0 +1,299 @@
+// Package auth stores and looks up the tokens used to access forge APIs.
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// ErrNoToken indicates that no token is available for a host
+var ErrNoToken = errors.New("no token found")
+
+// Source is where a token was found
+type Source string
+
+const (
+	SourceEnv        Source = "environment"
+	SourceConfig     Source = "config file"
+	SourceCredential Source = "git credential helper"
+)
+
+// Token is a credential for a single host
+type Token struct {
+	Host  string
+	Value string
+	User  string
+	// Source is where the token was found, EnvVar names the variable for
+	// tokens from the environment
+	Source Source
+	EnvVar string
+}
+
+// Masked returns the token with all but the last four characters hidden
+func (t *Token) Masked() string {
+	if len(t.Value) <= 4 {
+		return strings.Repeat("*", len(t.Value))
+	}
+	return strings.Repeat("*", len(t.Value)-4) + t.Value[len(t.Value)-4:]
+}
+
+// hostEntry is a host stored in the hosts file
+type hostEntry struct {
+	Token string `json:"token"`
+	User  string `json:"user,omitempty"`
+}
+
+type hostsFile struct {
+	Hosts map[string]hostEntry `json:"hosts"`
+}
+
+// HostsPath returns the file tokens are stored in. CLIBORG_CONFIG_DIR
+// overrides the user configuration directory.
+func HostsPath() (string, error) {
+	dir := os.Getenv("CLIBORG_CONFIG_DIR")
+	if dir == "" {
+		userDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("could not find the user config directory: %w", err)
+		}
+		dir = filepath.Join(userDir, "cliborg")
+	}
+	return filepath.Join(dir, "hosts.json"), nil
+}
+
+// envTokens lists the token variables of each forge and the hosts they are
+// meant for: a public instance and the variable naming a self-hosted one
+var envTokens = []struct {
+	vars    []string
+	host    string
+	hostVar string
+}{
+	{vars: []string{"CLIBORG_TOKEN"}, hostVar: "CLIBORG_HOST"},
+	{vars: []string{"GH_TOKEN", "GITHUB_TOKEN"}, host: "github.com", hostVar: "GH_HOST"},
+	{vars: []string{"GITLAB_TOKEN"}, host: "gitlab.com", hostVar: "GITLAB_HOST"},
+	{vars: []string{"BITBUCKET_TOKEN"}, host: "bitbucket.org"},
+	{vars: []string{"GITEA_TOKEN"}, hostVar: "GITEA_HOST"},
+}
+
+// EnvVars returns the environment variables checked for a host's token, in
+// order of precedence. Tokens only go to the hosts they were set for, so a
+// remote on another host never receives them.
+func EnvVars(host string) []string {
+	var vars []string
+	for _, t := range envTokens {
+		if host == t.host || (t.hostVar != "" && envHost(t.hostVar) == host) {
+			vars = append(vars, t.vars...)
+		}
+	}
+	return vars
+}
+
+// envHost returns the normalized host named by an environment variable
+func envHost(name string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		return ""
+	}
+	host, err := git.NormalizeHost(value)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// Lookup finds the token for a host, checking environment variables, then the
+// hosts file and finally the git credential helpers
+func Lookup(host string) (*Token, error) {
+	host, err := git.NormalizeHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range EnvVars(host) {
+		if value := os.Getenv(name); value != "" {
+			return &Token{Host: host, Value: value, Source: SourceEnv, EnvVar: name}, nil
+		}
+	}
+
+	hosts, err := readHosts()
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := hosts.Hosts[host]; ok && entry.Token != "" {
+		return &Token{Host: host, Value: entry.Token, User: entry.User, Source: SourceConfig}, nil
+	}
+
+	cred, err := git.CredentialFill("https", host)
+	if err == nil {
+		return &Token{Host: host, Value: cred.Password, User: cred.Username, Source: SourceCredential}, nil
+	}
+	if !errors.Is(err, git.ErrNoCredential) {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("%w for %s, run `cliborg auth login --hostname %s`", ErrNoToken, host, host)
+}
+
+// TokenFor returns the token value for a host, or "" when there is none so
+// that anonymous access is left to the forge to reject
+func TokenFor(host string) (string, error) {
+	token, err := Lookup(host)
+	if errors.Is(err, ErrNoToken) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return token.Value, nil
+}
+
+// DefaultHost returns the host used for "owner/name" shorthands: CLIBORG_HOST,
+// otherwise the only host in the hosts file, otherwise github.com
+func DefaultHost() (string, error) {
+	if host := os.Getenv("CLIBORG_HOST"); host != "" {
+		return git.NormalizeHost(host)
+	}
+
+	hosts, err := StoredHosts()
+	if err != nil {
+		return "", err
+	}
+	if len(hosts) == 1 {
+		return hosts[0], nil
+	}
+	return "github.com", nil
+}
+
+// Login stores a token for a host in the hosts file
+func Login(host, user, token string) error {
+	host, err := git.NormalizeHost(host)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return errors.New("token must not be empty")
+	}
+
+	hosts, err := readHosts()
+	if err != nil {
+		return err
+	}
+	hosts.Hosts[host] = hostEntry{Token: token, User: user}
+	return writeHosts(hosts)
+}
+
+// Logout removes the stored token of a host, reporting whether there was one
+func Logout(host string) (bool, error) {
+	host, err := git.NormalizeHost(host)
+	if err != nil {
+		return false, err
+	}
+
+	hosts, err := readHosts()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := hosts.Hosts[host]; !ok {
+		return false, nil
+	}
+	delete(hosts.Hosts, host)
+	return true, writeHosts(hosts)
+}
+
+// StoredHosts returns the hosts with a token in the hosts file, sorted
+func StoredHosts() ([]string, error) {
+	hosts, err := readHosts()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(hosts.Hosts))
+	for name := range hosts.Hosts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func readHosts() (*hostsFile, error) {
+	hosts := &hostsFile{Hosts: map[string]hostEntry{}}
+
+	path, err := HostsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return hosts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	// like ssh, refuse tokens other users could read
+	if perm := info.Mode().Perm(); runtime.GOOS != "windows" && perm&0077 != 0 {
+		return nil, fmt.Errorf("permissions %04o for %s are too open, run `chmod 600 %s`", perm, path, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, hosts); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if hosts.Hosts == nil {
+		hosts.Hosts = map[string]hostEntry{}
+	}
+	return hosts, nil
+}
+
+// writeHosts replaces the hosts file, readable by the current user only
+func writeHosts(hosts *hostsFile) error {
+	path, err := HostsPath()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(hosts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a failure never truncates the tokens
+	tmp, err := os.CreateTemp(dir, ".hosts-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnvVars(t *testing.T) {
+	for _, name := range []string{"CLIBORG_HOST", "GH_HOST", "GITLAB_HOST", "GITEA_HOST"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("CLIBORG_HOST", "https://git.example.com:8443/")
+	t.Setenv("GH_HOST", "GitHub.Example.com")
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{"github.com", []string{"GH_TOKEN", "GITHUB_TOKEN"}},
+		{"github.example.com", []string{"GH_TOKEN", "GITHUB_TOKEN"}},
+		{"gitlab.com", []string{"GITLAB_TOKEN"}},
+		{"git.example.com:8443", []string{"CLIBORG_TOKEN"}},
+		{"git.example.com", nil},
+		{"github.evil.example", nil},
+		{"gitlab.example.com", nil},
+		{"codeberg.org", nil},
+	}
+	for _, tt := range tests {
+		if got := EnvVars(tt.host); !slices.Equal(got, tt.want) {
+			t.Errorf("EnvVars(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestLookupEnv(t *testing.T) {
+	t.Setenv("CLIBORG_CONFIG_DIR", t.TempDir())
+	t.Setenv("CLIBORG_HOST", "")
+	t.Setenv("GH_HOST", "")
+	t.Setenv("CLIBORG_TOKEN", "global")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "gh")
+
+	token, err := Lookup("https://github.com/owner/name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Value != "gh" || token.EnvVar != "GITHUB_TOKEN" || token.Host != "github.com" {
+		t.Errorf("Lookup() = %+v, want GITHUB_TOKEN for github.com", token)
+	}
+
+	t.Setenv("CLIBORG_HOST", "github.com")
+	token, err = Lookup("github.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Value != "global" || token.EnvVar != "CLIBORG_TOKEN" {
+		t.Errorf("Lookup() = %+v, want CLIBORG_TOKEN to take precedence", token)
+	}
+}
@@ -0,0 +1,244 @@
+// Package buildinfo works out the version stamped into binaries, both for
+// projects built with cliborg and for cliborg itself.
+package buildinfo
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// Set with -ldflags "-X github.com/nick-ccc/CLIborg/internal/buildinfo.Version=..."
+// when building cliborg, see Current
+var (
+	Version = ""
+	Commit  = ""
+	Date    = ""
+)
+
+// DefaultTarget is the package the -X flags set variables in
+const DefaultTarget = "main"
+
+// untaggedVersion is used for commits without a release tag
+const untaggedVersion = "v0.0.0"
+
+// Info is the version information of a build
+type Info struct {
+	Version string
+	// Commit is the full hash of the built commit
+	Commit string
+	// Date is the committer date, so rebuilding a commit gives the same value
+	Date  time.Time
+	Dirty bool
+}
+
+// FromGit describes the checked out commit. The version is the tag for a
+// tagged, clean commit and a pseudo-version such as v1.2.3-5-gabc1234-dirty
+// otherwise, see git.Description. Commits without a tag get a v0.0.0
+// pseudo-version. The prefix is removed from the tag, e.g. "svc-a/".
+func FromGit(opts git.DescribeOptions, prefix string) (*Info, error) {
+	opts.Always = true
+	d, err := git.Describe(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	commit, err := git.LastCommit(ref)
+	if err != nil {
+		return nil, err
+	}
+	date, err := git.CommitDate(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	version := strings.TrimPrefix(d.String(), prefix)
+	if d.Tag == "" {
+		version = fmt.Sprintf("%s-g%s", untaggedVersion, d.String())
+	}
+
+	return &Info{
+		Version: version,
+		Commit:  commit.Hash,
+		Date:    date.UTC(),
+		Dirty:   d.Dirty,
+	}, nil
+}
+
+// Current returns the build information of the running cliborg binary: the
+// values set with -ldflags, otherwise those recorded by the go command
+func Current() Info {
+	info := Info{Version: Version, Commit: Commit}
+	if Date != "" {
+		info.Date, _ = time.Parse(time.RFC3339, Date)
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Date.IsZero() {
+				info.Date, _ = time.Parse(time.RFC3339, s.Value)
+			}
+		case "vcs.modified":
+			if Version == "" {
+				info.Dirty = s.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+	return info
+}
+
+// String formats the information for --version output, e.g.
+// "v1.2.3 (abc1234, 2025-01-31)"
+func (i Info) String() string {
+	var details []string
+	if i.Commit != "" {
+		commit := i.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		if i.Dirty {
+			commit += ", modified"
+		}
+		details = append(details, commit)
+	}
+	if !i.Date.IsZero() {
+		details = append(details, i.Date.Format("2006-01-02"))
+	}
+	if len(details) == 0 {
+		return i.Version
+	}
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(details, ", "))
+}
+
+// LDFlags returns the -X flags setting the version, commit, date and dirty
+// string variables of the target package, e.g. "main" or an import path
+func (i Info) LDFlags(target string) string {
+	if target == "" {
+		target = DefaultTarget
+	}
+	values := []struct{ name, value string }{
+		{"version", i.Version},
+		{"commit", i.Commit},
+		{"date", i.Date.Format(time.RFC3339)},
+		{"dirty", strconv.FormatBool(i.Dirty)},
+	}
+
+	flags := make([]string, 0, len(values))
+	for _, v := range values {
+		flags = append(flags, fmt.Sprintf("-X %s.%s=%s", target, v.name, v.value))
+	}
+	return strings.Join(flags, " ")
+}
+
+var goFileTemplate = template.Must(template.New("version.go").Parse(`// Code generated by "cliborg version ldflags --go-file"; DO NOT EDIT.
+
+package {{.Package}}
+
+// Build information of the commit this file was generated from
+const (
+	Version = {{printf "%q" .Info.Version}}
+	Commit  = {{printf "%q" .Info.Commit}}
+	Date    = {{printf "%q" .Date}}
+	Dirty   = {{.Info.Dirty}}
+)
+`))
+
+// GoFile renders a Go source file declaring the information as constants of
+// the named package
+func (i Info) GoFile(pkg string) ([]byte, error) {
+	if pkg == "" {
+		pkg = DefaultTarget
+	}
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	var buf bytes.Buffer
+	err := goFileTemplate.Execute(&buf, struct {
+		Package string
+		Info    Info
+		Date    string
+	}{pkg, i, i.Date.Format(time.RFC3339)})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// PackageName returns the package of the Go files in dir, leaving out tests
+// and the file skip that is about to be replaced. Without other Go files the
+// directory name is used.
+func PackageName(dir, skip string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	fset := token.NewFileSet()
+	var fallback string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		f, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		if sameFile(path, skip) {
+			fallback = f.Name.Name
+			continue
+		}
+		return f.Name.Name, nil
+	}
+	if fallback != "" {
+		return fallback, nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(abs)
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("directory name %q is not a valid package name", name)
+	}
+	return name, nil
+}
+
+func sameFile(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && a == b
+}
@@ -0,0 +1,57 @@
+package buildinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackageName(t *testing.T) {
+	write := func(t *testing.T, dir, name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root := t.TempDir()
+	app := filepath.Join(root, "cmd", "app")
+	write(t, app, "main.go", "// Command app does things.\npackage main\n\nfunc main() {}\n")
+	write(t, app, "main_test.go", "package main_test\n")
+
+	regenerated := filepath.Join(root, "build")
+	write(t, regenerated, "version.go", "package build\n")
+
+	tests := []struct {
+		name    string
+		dir     string
+		skip    string
+		want    string
+		wantErr bool
+	}{
+		{name: "package clause of other files", dir: app, skip: filepath.Join(app, "version.go"), want: "main"},
+		{name: "file being replaced", dir: regenerated, skip: filepath.Join(regenerated, "version.go"), want: "build"},
+		{name: "no go files", dir: filepath.Join(root, "internal", "version"), want: "version"},
+		{name: "invalid directory name", dir: filepath.Join(root, "my-pkg"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PackageName(tt.dir, tt.skip)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PackageName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PackageName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoFileInvalidPackage(t *testing.T) {
+	if _, err := (Info{Version: "v1.0.0"}).GoFile("my-pkg"); err == nil {
+		t.Error("expected an error for an invalid package name")
+	}
+}
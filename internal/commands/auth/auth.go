@@ -0,0 +1,231 @@
+// Package auth implements `cliborg auth`.
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/nick-ccc/CLIborg/internal/auth"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// NewCmdAuth returns the `auth` command and its subcommands
+func NewCmdAuth() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth <command>",
+		Short: "Manage forge API tokens",
+		Long: `Manage the tokens used to talk to GitHub and GitLab.
+
+Tokens are looked up per host in this order:
+  1. CLIBORG_TOKEN for the host in CLIBORG_HOST, GH_TOKEN/GITHUB_TOKEN for
+     github.com or GH_HOST, GITLAB_TOKEN for gitlab.com or GITLAB_HOST
+  2. the hosts file written by "cliborg auth login"
+  3. git credential helpers ("git credential fill")`,
+	}
+
+	cmd.AddCommand(newCmdLogin())
+	cmd.AddCommand(newCmdStatus())
+	cmd.AddCommand(newCmdLogout())
+
+	return cmd
+}
+
+func newCmdLogin() *cobra.Command {
+	var (
+		hostname  string
+		user      string
+		withToken bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Store a token for a host",
+		Long: `Store a token for a host in the hosts file, readable only by the current user.
+
+The host defaults to the host of the base remote. The token is prompted for
+on a terminal, or read from standard input with --with-token.`,
+		Example: `  cliborg auth login
+  echo "$TOKEN" | cliborg auth login --hostname gitlab.example.com --with-token`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := resolveHost(hostname)
+			if err != nil {
+				return err
+			}
+
+			token, err := readToken(cmd.InOrStdin(), cmd.ErrOrStderr(), host, withToken)
+			if err != nil {
+				return err
+			}
+
+			if err := auth.Login(host, user, token); err != nil {
+				return err
+			}
+
+			path, _ := auth.HostsPath()
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged in to %s, token stored in %s\n", host, path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&hostname, "hostname", "", "Host to log in to, defaults to the base remote host")
+	cmd.Flags().StringVar(&user, "user", "", "User name stored alongside the token")
+	cmd.Flags().BoolVar(&withToken, "with-token", false, "Read the token from standard input")
+
+	return cmd
+}
+
+func newCmdStatus() *cobra.Command {
+	var (
+		hostname  string
+		showToken bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show which hosts have a token and where it comes from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			var hosts []string
+			if hostname != "" {
+				host, err := git.NormalizeHost(hostname)
+				if err != nil {
+					return err
+				}
+				hosts = []string{host}
+			} else {
+				stored, err := auth.StoredHosts()
+				if err != nil {
+					return err
+				}
+				hosts = stored
+				// the base remote host is always reported, even without a token
+				if host, err := resolveHost(""); err == nil && !slices.Contains(hosts, host) {
+					hosts = append([]string{host}, hosts...)
+				}
+			}
+
+			if len(hosts) == 0 {
+				fmt.Fprintln(out, "No hosts configured, run `cliborg auth login --hostname <host>`")
+				return nil
+			}
+
+			missing := false
+			for _, host := range hosts {
+				token, err := auth.Lookup(host)
+				if errors.Is(err, auth.ErrNoToken) {
+					missing = true
+					fmt.Fprintf(out, "%s\n  x not logged in\n", host)
+					continue
+				}
+				if err != nil {
+					return err
+				}
+
+				source := string(token.Source)
+				if token.EnvVar != "" {
+					source = fmt.Sprintf("%s (%s)", token.Source, token.EnvVar)
+				}
+				value := token.Masked()
+				if showToken {
+					value = token.Value
+				}
+
+				fmt.Fprintf(out, "%s\n  ✓ token from %s\n  token: %s\n", host, source, value)
+				if token.User != "" {
+					fmt.Fprintf(out, "  user: %s\n", token.User)
+				}
+			}
+
+			if missing && hostname != "" {
+				return auth.ErrNoToken
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&hostname, "hostname", "", "Only check this host")
+	cmd.Flags().BoolVar(&showToken, "show-token", false, "Print the token instead of masking it")
+
+	return cmd
+}
+
+func newCmdLogout() *cobra.Command {
+	var hostname string
+
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the stored token of a host",
+		Long: `Remove the stored token of a host from the hosts file.
+
+Tokens from environment variables and git credential helpers are not touched.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := resolveHost(hostname)
+			if err != nil {
+				return err
+			}
+
+			removed, err := auth.Logout(host)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("not logged in to %s", host)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged out of %s\n", host)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&hostname, "hostname", "", "Host to log out of, defaults to the base remote host")
+
+	return cmd
+}
+
+// resolveHost normalizes the given host, defaulting to the base remote
+func resolveHost(hostname string) (string, error) {
+	if hostname != "" {
+		return git.NormalizeHost(hostname)
+	}
+
+	project, err := git.RemoteProject(git.BaseRemoteName())
+	if err != nil {
+		return "", fmt.Errorf("no --hostname given and no remote to take it from: %w", err)
+	}
+	return project.Host, nil
+}
+
+// readToken prompts for the token on a terminal or reads it from in
+func readToken(in io.Reader, errOut io.Writer, host string, fromStdin bool) (string, error) {
+	if f, ok := in.(*os.File); ok && !fromStdin && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprintf(errOut, "Paste a token for %s: ", host)
+		data, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(errOut)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("reading token: %w", err)
+	}
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", errors.New("no token given on standard input")
+	}
+	return token, nil
+}
@@ -0,0 +1,43 @@
+// Package branch implements `cliborg branch`.
+package branch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// NewCmdBranch returns the `branch` command and its subcommands
+func NewCmdBranch() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch <command>",
+		Short: "Create, switch and clean up local branches",
+	}
+
+	cmd.AddCommand(newCmdCreate())
+	cmd.AddCommand(newCmdSwitch())
+	cmd.AddCommand(newCmdDelete())
+	cmd.AddCommand(newCmdPrune())
+
+	return cmd
+}
+
+// defaultBranch returns the name of the default branch of the remote and the
+// ref branches are checked against, the remote-tracking branch when fetched
+func defaultBranch(remote string) (string, string, error) {
+	if remote == "" {
+		remote = git.BaseRemoteName()
+	}
+	name, err := git.RemoteDefaultBranch(remote)
+	if err != nil {
+		return "", "", err
+	}
+
+	tracking := fmt.Sprintf("%s/%s", remote, name)
+	if git.RefExists("refs/remotes/" + tracking) {
+		return name, tracking, nil
+	}
+	return name, name, nil
+}
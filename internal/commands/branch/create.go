@@ -0,0 +1,57 @@
+package branch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+func newCmdCreate() *cobra.Command {
+	var (
+		base       string
+		noCheckout bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a branch from a base ref and switch to it",
+		Long: `Create a branch from a base ref and switch to it.
+
+The base defaults to the default branch of the base remote, so new work does
+not start from whatever happens to be checked out.`,
+		Example: `  cliborg branch create feat/login
+  cliborg branch create fix/crash --base v1.2.0 --no-checkout`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if base == "" {
+				_, ref, err := defaultBranch("")
+				if err != nil {
+					return err
+				}
+				base = ref
+			}
+
+			if err := git.CreateBranch(name, base, !noCheckout); err != nil {
+				return err
+			}
+
+			if noCheckout {
+				fmt.Fprintf(cmd.OutOrStdout(), "Created branch %s from %s\n", name, base)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Created branch %s from %s and switched to it\n", name, base)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&base, "base", "", "Ref to branch from, defaults to the default branch of the base remote")
+	_ = cmd.RegisterFlagCompletionFunc("base", completion.Branches)
+	cmd.Flags().BoolVar(&noCheckout, "no-checkout", false, "Create the branch without switching to it")
+
+	return cmd
+}
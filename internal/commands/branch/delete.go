@@ -0,0 +1,128 @@
+package branch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+type deleteOptions struct {
+	Names  []string
+	Merged bool
+	Into   string
+	Force  bool
+	DryRun bool
+
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+func newCmdDelete() *cobra.Command {
+	opts := &deleteOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "delete [<name>...]",
+		Short: "Delete branches that are merged",
+		Long: `Delete local branches, or with --merged every branch merged into the default
+branch.
+
+Branches with commits missing from the default branch (or --into) are kept
+unless --force is given. The checked out branch and the default branch are
+never deleted.`,
+		Example: `  cliborg branch delete feat/login
+  cliborg branch delete --merged --dry-run
+  cliborg branch delete spike --force`,
+		ValidArgsFunction: completion.Branches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Names = args
+			if opts.Merged == (len(args) > 0) {
+				return errors.New("give branch names or --merged")
+			}
+			if opts.Merged && opts.Force {
+				return errors.New("--force cannot be used with --merged")
+			}
+			opts.Out = cmd.OutOrStdout()
+			opts.ErrOut = cmd.ErrOrStderr()
+			return runDelete(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Merged, "merged", false, "Delete every branch merged into the default branch")
+	cmd.Flags().StringVar(&opts.Into, "into", "", "Ref branches must be merged into, defaults to the default branch of the base remote")
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Delete branches even when they are not merged")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the branches that would be deleted")
+	_ = cmd.RegisterFlagCompletionFunc("into", completion.Branches)
+
+	return cmd
+}
+
+func runDelete(opts *deleteOptions) error {
+	defaultName, into, err := defaultBranch("")
+	if err != nil {
+		return err
+	}
+	if opts.Into != "" {
+		into = opts.Into
+	}
+
+	names := opts.Names
+	if opts.Merged {
+		merged, err := git.MergedBranches(into)
+		if err != nil {
+			return err
+		}
+		current, _ := git.CurrentBranch()
+		names = nil
+		for _, name := range merged {
+			if name != current && name != defaultName && name != into {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Fprintf(opts.Out, "No branches merged into %s\n", into)
+			return nil
+		}
+	}
+
+	return deleteBranches(opts.Out, opts.ErrOut, names, defaultName, into, opts.Force, opts.DryRun)
+}
+
+// deleteBranches deletes each branch, reporting failures without stopping.
+// The default branch is refused even when forced.
+func deleteBranches(out, errOut io.Writer, names []string, defaultName, into string, force, dryRun bool) error {
+	failed := 0
+	for _, name := range names {
+		if name == defaultName {
+			err := fmt.Errorf("%w: %s", git.ErrDefaultBranch, name)
+			if len(names) == 1 {
+				return err
+			}
+			fmt.Fprintf(errOut, "Skipped %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		if dryRun {
+			fmt.Fprintf(out, "Would delete %s\n", name)
+			continue
+		}
+		if err := git.DeleteBranch(name, into, force); err != nil {
+			if len(names) == 1 {
+				return err
+			}
+			fmt.Fprintf(errOut, "Skipped %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(out, "Deleted %s\n", name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d branches not deleted", failed, len(names))
+	}
+	return nil
+}
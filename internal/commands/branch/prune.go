@@ -0,0 +1,79 @@
+package branch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+func newCmdPrune() *cobra.Command {
+	var (
+		remote  string
+		into    string
+		noFetch bool
+		force   bool
+		dryRun  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete branches whose upstream branch is gone",
+		Long: `Fetch the remote with --prune and delete the local branches whose upstream
+branch was deleted, e.g. after their merge request was merged.
+
+Branches with commits missing from the default branch (or --into) are kept
+unless --force is given, which is needed for squash merged branches. The
+checked out branch and the default branch are never deleted.`,
+		Example: `  cliborg branch prune --dry-run
+  cliborg branch prune --force`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if remote == "" {
+				remote = git.BaseRemoteName()
+			}
+			if !noFetch {
+				if err := git.FetchPrune(remote); err != nil {
+					return err
+				}
+			}
+
+			defaultName, ref, err := defaultBranch(remote)
+			if err != nil {
+				return err
+			}
+			if into == "" {
+				into = ref
+			}
+
+			branches, err := git.LocalBranches()
+			if err != nil {
+				return err
+			}
+			var gone []string
+			for _, b := range branches {
+				if b.Gone {
+					gone = append(gone, b.Name)
+				}
+			}
+			if len(gone) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No branches with a deleted upstream")
+				return nil
+			}
+
+			return deleteBranches(cmd.OutOrStdout(), cmd.ErrOrStderr(), gone, defaultName, into, force, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&remote, "remote", "", "Remote to fetch, defaults to the base remote")
+	cmd.Flags().StringVar(&into, "into", "", "Ref branches must be merged into, defaults to the default branch of the remote")
+	cmd.Flags().BoolVar(&noFetch, "no-fetch", false, "Use the remote-tracking branches as they are")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Delete branches even when they are not merged")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the branches that would be deleted")
+	_ = cmd.RegisterFlagCompletionFunc("remote", completion.Remotes)
+	_ = cmd.RegisterFlagCompletionFunc("into", completion.Branches)
+
+	return cmd
+}
@@ -0,0 +1,36 @@
+package branch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+func newCmdSwitch() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "switch <name>",
+		Short: "Switch to a branch",
+		Long: `Switch to a local branch. A branch that only exists on a remote is checked
+out as a new local branch tracking it.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Branches),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if current, err := git.CurrentBranch(); err == nil && current == name {
+				fmt.Fprintf(cmd.OutOrStdout(), "Already on %s\n", name)
+				return nil
+			}
+
+			if err := git.CheckoutBranch(name); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Switched to %s\n", name)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,118 @@
+package changelog
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+)
+
+type backfillOptions struct {
+	Package   string
+	Remote    string
+	Overwrite bool
+	DryRun    bool
+
+	Out io.Writer
+}
+
+func newCmdBackfill() *cobra.Command {
+	opts := &backfillOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Write changelog files for releases tagged without one",
+		Long: `Write a changelog file for every release tag that has none, generated from the
+commit history between the tags and dated with the tag date.
+
+Tags are walked in semantic version order. A final release lists the changes
+since the previous final release, a pre-release the changes since the tag
+before it. Existing changelog files are left alone unless --overwrite is given,
+and tags without changelog entries are skipped.
+
+The files are written to the working tree only, review and commit them.`,
+		Example: `  cliborg changelog backfill --dry-run
+  cliborg changelog backfill --package svc-a --overwrite`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Out = cmd.OutOrStdout()
+			return runBackfill(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Package, "package", "p", "", "Backfill a single package of a monorepo")
+	cmd.Flags().StringVar(&opts.Remote, "remote", "", "Remote used for links, defaults to the base remote")
+	cmd.Flags().BoolVar(&opts.Overwrite, "overwrite", false, "Generate existing changelog files again")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the changelogs without writing them")
+	_ = cmd.RegisterFlagCompletionFunc("package", completion.Packages)
+	_ = cmd.RegisterFlagCompletionFunc("remote", completion.Remotes)
+
+	return cmd
+}
+
+func runBackfill(opts *backfillOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	pkgs, err := repository.PackagesFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if opts.Package != "" {
+		pkg, err := repository.FindPackage(pkgs, opts.Package)
+		if err != nil {
+			return err
+		}
+		pkgs = []repository.Package{pkg}
+	}
+
+	gen, err := repository.GenerateOptionsFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	gen.Remote = opts.Remote
+	if gen.Remote == "" {
+		gen.Remote = git.BaseRemoteName()
+	}
+
+	written := 0
+	for _, pkg := range pkgs {
+		items, err := repository.PlanBackfill(pkg, gen, opts.Overwrite)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			switch {
+			case item.Changelog == nil:
+				fmt.Fprintf(opts.Out, "%s: %s exists, skipping\n", item.Tag, item.Path)
+				continue
+			case len(item.Changelog.Entries) == 0:
+				fmt.Fprintf(opts.Out, "%s: no changelog entries, skipping\n", item.Tag)
+				continue
+			}
+
+			if opts.DryRun {
+				fmt.Fprintf(opts.Out, "\n%s:\n\n%s\n", item.Path, item.Changelog)
+				continue
+			}
+			if err := repository.WriteChangelog(item.Path, item.Changelog); err != nil {
+				return err
+			}
+			fmt.Fprintf(opts.Out, "%s: wrote %s\n", item.Tag, item.Path)
+			written++
+		}
+	}
+
+	if !opts.DryRun {
+		fmt.Fprintf(opts.Out, "Wrote %d changelog file(s)\n", written)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// Package changelog implements `cliborg changelog`.
+package changelog
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCmdChangelog returns the `changelog` command and its subcommands
+func NewCmdChangelog() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "changelog <command>",
+		Short: "Read and maintain changelog files",
+	}
+
+	cmd.AddCommand(newCmdBackfill())
+	cmd.AddCommand(newCmdShow())
+
+	return cmd
+}
@@ -0,0 +1,163 @@
+package changelog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+)
+
+// Output formats of `changelog show`
+const (
+	FormatMarkdown = "markdown"
+	FormatPlain    = "plain"
+	FormatJSON     = "json"
+)
+
+type showOptions struct {
+	Version     string
+	Package     string
+	Format      string
+	StripHeader bool
+
+	Out io.Writer
+}
+
+// notesJSON is the JSON output of `changelog show`
+type notesJSON struct {
+	Version    string        `json:"version"`
+	Date       string        `json:"date,omitempty"`
+	CompareURL string        `json:"compareUrl,omitempty"`
+	Path       string        `json:"path"`
+	Sections   []sectionJSON `json:"sections"`
+	Markdown   string        `json:"markdown"`
+}
+
+type sectionJSON struct {
+	Name    string   `json:"name"`
+	Entries []string `json:"entries"`
+}
+
+func newCmdShow() *cobra.Command {
+	opts := &showOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "show <version>",
+		Short: "Print the release notes of a single version",
+		Long: `Print the changelog of a single release, e.g. for a forge release description
+or an announcement.
+
+The per-version changelog file written by "cliborg release" is read when it
+exists, otherwise the section of the version in a combined CHANGELOG.md in the
+changelog dir or the current directory.
+
+The notes are printed as markdown, plain text without links and emphasis, or
+JSON with the entries grouped by section. Use --strip-header to leave out the
+HTML header block of per-version files.`,
+		Example: `  cliborg changelog show v1.2.0
+  cliborg changelog show 1.2.0 --strip-header
+  cliborg changelog show svc-a/v0.3.0 --format json`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.ChangelogVersions),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Version = args[0]
+			opts.Out = cmd.OutOrStdout()
+			switch opts.Format {
+			case FormatMarkdown, "md", FormatPlain, FormatJSON:
+			default:
+				return fmt.Errorf("invalid format %q, expected %s, %s or %s", opts.Format, FormatMarkdown, FormatPlain, FormatJSON)
+			}
+			return runShow(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Package, "package", "p", "", "Package of a monorepo, detected from the tag prefix by default")
+	cmd.Flags().StringVarP(&opts.Format, "format", "F", FormatMarkdown, "Output format: markdown, plain or json")
+	cmd.Flags().BoolVar(&opts.StripHeader, "strip-header", false, "Leave out the HTML header block")
+	_ = cmd.RegisterFlagCompletionFunc("package", completion.Packages)
+	_ = cmd.RegisterFlagCompletionFunc("format", completion.Fixed(FormatMarkdown, FormatPlain, FormatJSON))
+
+	return cmd
+}
+
+func runShow(opts *showOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	pkg, err := showPackage(cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	notes, err := repository.FindReleaseNotes(pkg, opts.Version)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		out := notesJSON{
+			Version:    notes.Version,
+			Date:       notes.Date,
+			CompareURL: notes.CompareURL,
+			Path:       notes.Path,
+			Sections:   []sectionJSON{},
+			Markdown:   notes.Markdown,
+		}
+		for _, s := range notes.Sections() {
+			out.Sections = append(out.Sections, sectionJSON{Name: s.Name, Entries: s.Entries})
+		}
+		enc := json.NewEncoder(opts.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+
+	case FormatPlain:
+		if !opts.StripHeader && notes.Version != "" {
+			title := notes.Version
+			if notes.Date != "" {
+				title += " - " + notes.Date
+			}
+			fmt.Fprintf(opts.Out, "%s\n%s\n\n", title, strings.Repeat("=", len(title)))
+		}
+		fmt.Fprint(opts.Out, repository.PlainText(notes.Markdown))
+
+	default:
+		if !opts.StripHeader && notes.Header != "" {
+			fmt.Fprintf(opts.Out, "%s\n", notes.Header)
+		}
+		fmt.Fprint(opts.Out, notes.Markdown)
+	}
+
+	return nil
+}
+
+// showPackage returns the package named by --package, or the one whose tag
+// prefix the version starts with
+func showPackage(cfg *config.Config, opts *showOptions) (repository.Package, error) {
+	pkgs, err := repository.PackagesFromConfig(cfg)
+	if err != nil {
+		return repository.Package{}, err
+	}
+	if opts.Package != "" {
+		return repository.FindPackage(pkgs, opts.Package)
+	}
+	if len(pkgs) == 1 {
+		return pkgs[0], nil
+	}
+
+	for _, p := range pkgs {
+		if p.TagPrefix != "" && strings.HasPrefix(opts.Version, p.TagPrefix) {
+			return p, nil
+		}
+	}
+	return repository.Package{}, errors.New("--package is required when the version has no package tag prefix")
+}
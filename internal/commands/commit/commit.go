@@ -0,0 +1,65 @@
+// Package commit implements `cliborg commit`.
+package commit
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+)
+
+// NewCmdCommit returns the `commit` command and its subcommands
+func NewCmdCommit() *cobra.Command {
+	opts := &createOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "commit [flags]",
+		Short: "Write and check Conventional Commits",
+		Long: `Build a Conventional Commit message and commit the staged changes.
+
+On a terminal every part of the message not given as a flag is asked for: the
+type, scope, description, body, whether the change is breaking and the issues
+it references. Scripts pass --type and --message, nothing is asked then.
+
+The message is checked against the "lint" section of .cliborg.json before
+committing. Types listed in the changelog are ` + strings.Join(changelogTypes(), ", ") + `.
+
+Use --fragment to also write a changelog fragment to the unreleased directory of
+the changelog dir. The fragment is committed with the change and can be edited
+before the release; the next release lists it instead of the commit subject and
+removes it.`,
+		Example: `  cliborg commit
+  cliborg commit --type feat --scope api --message "add pagination" --ref #12
+  cliborg commit -t fix -m "handle empty tags" --fragment -a
+  cliborg commit -t feat -m "drop the v1 endpoints" --breaking-note "v1 is gone"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.AskBreaking = !cmd.Flags().Changed("breaking") && !cmd.Flags().Changed("breaking-note")
+			opts.AskFragment = !cmd.Flags().Changed("fragment")
+			opts.In = cmd.InOrStdin()
+			opts.Out = cmd.OutOrStdout()
+			opts.ErrOut = cmd.ErrOrStderr()
+			return runCreate(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Type, "type", "t", "", "Type of the change, e.g. feat or fix")
+	cmd.Flags().StringVarP(&opts.Scope, "scope", "s", "", "Scope of the change")
+	cmd.Flags().StringVarP(&opts.Subject, "message", "m", "", "Short description of the change")
+	cmd.Flags().StringVarP(&opts.Body, "body", "b", "", "Longer description of the change")
+	cmd.Flags().BoolVar(&opts.Breaking, "breaking", false, "Mark the change as breaking")
+	cmd.Flags().StringVar(&opts.BreakingNote, "breaking-note", "", "Describe the breaking change in a BREAKING CHANGE footer, implies --breaking")
+	cmd.Flags().StringArrayVarP(&opts.Refs, "ref", "r", nil, "Reference an issue, e.g. #12, can be repeated")
+	cmd.Flags().BoolVar(&opts.Fragment, "fragment", false, "Write a changelog fragment for the change")
+	cmd.Flags().StringVarP(&opts.Package, "package", "p", "", "Package of a monorepo the fragment belongs to")
+	cmd.Flags().BoolVarP(&opts.All, "all", "a", false, "Stage modified and deleted tracked files first")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the message without committing")
+	_ = cmd.RegisterFlagCompletionFunc("type", completion.CommitTypes)
+	_ = cmd.RegisterFlagCompletionFunc("scope", completion.Scopes)
+	_ = cmd.RegisterFlagCompletionFunc("package", completion.Packages)
+
+	cmd.AddCommand(newCmdLint())
+
+	return cmd
+}
@@ -0,0 +1,374 @@
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/conventional"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/prompt"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+)
+
+// RefsTrailer lists the issues a commit refers to
+const RefsTrailer = "Refs"
+
+type createOptions struct {
+	Type         string
+	Scope        string
+	Subject      string
+	Body         string
+	Breaking     bool
+	BreakingNote string
+	Refs         []string
+	Fragment     bool
+	Package      string
+	All          bool
+	DryRun       bool
+
+	// AskBreaking and AskFragment are false when the flags were given
+	AskBreaking bool
+	AskFragment bool
+
+	// prompter is set when the answers are read from a terminal
+	prompter *prompt.Prompter
+	// fragmentPath is the fragment written for the commit
+	fragmentPath string
+
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+func runCreate(opts *createOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	rules := LintRules(cfg.Lint)
+
+	if isTerminal(opts.In) {
+		opts.prompter = prompt.New(opts.In, opts.ErrOut)
+	}
+	interactive := opts.Type == "" || opts.Subject == ""
+	if interactive {
+		if opts.prompter == nil {
+			return errors.New("--type and --message are required when not running on a terminal")
+		}
+		if err := ask(opts, rules); err != nil {
+			return err
+		}
+	}
+	if opts.BreakingNote != "" {
+		opts.Breaking = true
+	}
+
+	subject := header(opts)
+	body := messageBody(opts)
+
+	message := subject + "\n"
+	if body != "" {
+		message += "\n" + body + "\n"
+	}
+	errorCount, _ := Lint(opts.ErrOut, []Message{{Name: "commit message", Message: message}}, rules)
+	if errorCount > 0 {
+		return ErrLintFailed
+	}
+
+	var pkg repository.Package
+	var entry repository.Entry
+	if opts.Fragment {
+		pkg, err = fragmentPackage(opts, cfg)
+		if err != nil {
+			return err
+		}
+		entry, err = fragmentEntry(opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.DryRun {
+		fmt.Fprint(opts.Out, message)
+		if opts.Fragment {
+			fmt.Fprintf(opts.Out, "\nWould write a %s changelog fragment to %s\n", entry.Section, pkg.FragmentDir())
+		}
+		return nil
+	}
+
+	if interactive {
+		// show the message built from the answers before committing
+		fmt.Fprintf(opts.ErrOut, "\n%s\n", message)
+		ok, err := opts.prompter.Confirm("Commit?", true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("commit aborted")
+		}
+	}
+
+	commit := cfg.Commit.CommitOptions(subject)
+	commit.Body = body
+	commit.All = opts.All
+
+	if opts.Fragment {
+		opts.fragmentPath, err = writeFragment(cfg, pkg, entry, opts)
+		if err != nil {
+			return err
+		}
+		commit.Body = messageBody(opts)
+	}
+
+	sha, err := git.CommitWithOptions(commit)
+	if err != nil {
+		if opts.fragmentPath != "" {
+			// nothing was committed, do not leave the fragment behind
+			if rmErr := repository.RemoveFragments([]string{opts.fragmentPath}); rmErr != nil {
+				fmt.Fprintf(opts.ErrOut, "warning: %v\n", rmErr)
+			}
+		}
+		return err
+	}
+
+	fmt.Fprintf(opts.Out, "[%s] %s\n", sha[:min(7, len(sha))], subject)
+	if opts.fragmentPath != "" {
+		fmt.Fprintf(opts.Out, "Wrote changelog fragment %s\n", opts.fragmentPath)
+	}
+	return nil
+}
+
+// ask prompts for every part of the message that was not given as a flag
+func ask(opts *createOptions, rules conventional.Rules) error {
+	p := opts.prompter
+
+	if opts.Type == "" {
+		labels := make([]string, 0, len(rules.Types))
+		def := -1
+		for i, t := range rules.Types {
+			section, ok := repository.SectionForType(t)
+			if !ok {
+				section = "not in the changelog"
+			}
+			labels = append(labels, fmt.Sprintf("%-10s %s", t, section))
+			if t == "feat" {
+				def = i
+			}
+		}
+		i, err := p.Select("Type of change", labels, def)
+		if err != nil {
+			return err
+		}
+		opts.Type = rules.Types[i]
+	}
+
+	if opts.Scope == "" {
+		if len(rules.Scopes) > 0 {
+			labels := rules.Scopes
+			if !rules.RequireScope {
+				labels = append([]string{"(none)"}, labels...)
+			}
+			i, err := p.Select("Scope", labels, -1)
+			if err != nil {
+				return err
+			}
+			if labels[i] != "(none)" {
+				opts.Scope = labels[i]
+			}
+		} else {
+			scope, err := p.Input("Scope (optional)", "")
+			if err != nil {
+				return err
+			}
+			opts.Scope = scope
+		}
+	}
+
+	for opts.Subject == "" {
+		subject, err := p.Input("Short description in the imperative mood", "")
+		if err != nil {
+			return err
+		}
+		opts.Subject = subject
+	}
+
+	if opts.Body == "" {
+		body, err := p.Input("Longer description (optional)", "")
+		if err != nil {
+			return err
+		}
+		opts.Body = body
+	}
+
+	if opts.AskBreaking {
+		breaking, err := p.Confirm("Is this a breaking change?", false)
+		if err != nil {
+			return err
+		}
+		opts.Breaking = breaking
+	}
+	if opts.Breaking && opts.BreakingNote == "" {
+		note, err := p.Input("Describe the breaking change (optional)", "")
+		if err != nil {
+			return err
+		}
+		opts.BreakingNote = note
+	}
+
+	if len(opts.Refs) == 0 {
+		refs, err := p.Input("Issue references, e.g. #12 (optional, comma separated)", "")
+		if err != nil {
+			return err
+		}
+		for _, ref := range strings.Split(refs, ",") {
+			if ref = strings.TrimSpace(ref); ref != "" {
+				opts.Refs = append(opts.Refs, ref)
+			}
+		}
+	}
+
+	if _, ok := repository.SectionForType(opts.Type); opts.AskFragment && (ok || opts.Breaking) {
+		fragment, err := p.Confirm("Write a changelog fragment?", false)
+		if err != nil {
+			return err
+		}
+		opts.Fragment = fragment
+	}
+
+	return nil
+}
+
+// header returns the `type(scope)!: subject` line
+func header(opts *createOptions) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(opts.Type))
+	if scope := strings.TrimSpace(opts.Scope); scope != "" {
+		fmt.Fprintf(&sb, "(%s)", scope)
+	}
+	if opts.Breaking {
+		sb.WriteString("!")
+	}
+	sb.WriteString(": ")
+	sb.WriteString(strings.TrimSpace(opts.Subject))
+	return sb.String()
+}
+
+// messageBody returns the body followed by the breaking change, reference
+// and fragment footers
+func messageBody(opts *createOptions) string {
+	var paragraphs []string
+	if body := strings.TrimSpace(opts.Body); body != "" {
+		paragraphs = append(paragraphs, body)
+	}
+
+	var footers []string
+	if note := strings.TrimSpace(opts.BreakingNote); note != "" {
+		footers = append(footers, "BREAKING CHANGE: "+note)
+	}
+	if len(opts.Refs) > 0 {
+		footers = append(footers, git.Trailer{Token: RefsTrailer, Value: strings.Join(opts.Refs, ", ")}.String())
+	}
+	if opts.fragmentPath != "" {
+		footers = append(footers, git.Trailer{Token: repository.FragmentTrailer, Value: filepath.ToSlash(opts.fragmentPath)}.String())
+	}
+	if len(footers) > 0 {
+		paragraphs = append(paragraphs, strings.Join(footers, "\n"))
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// fragmentEntry returns the changelog entry described by the commit
+func fragmentEntry(opts *createOptions) (repository.Entry, error) {
+	section, ok := repository.SectionForType(opts.Type)
+	if !ok && !opts.Breaking {
+		return repository.Entry{}, fmt.Errorf("%s commits are left out of the changelog, a fragment would not be listed", opts.Type)
+	}
+	if !ok {
+		section = repository.SectionChanged
+	}
+
+	description := strings.TrimSpace(opts.Subject)
+	if len(opts.Refs) > 0 {
+		description = fmt.Sprintf("%s (%s)", description, strings.Join(opts.Refs, ", "))
+	}
+
+	return repository.Entry{
+		Section:     section,
+		Description: description,
+		Scope:       strings.TrimSpace(opts.Scope),
+		Breaking:    opts.Breaking,
+	}, nil
+}
+
+// fragmentPackage returns the package the fragment belongs to, asking on a
+// terminal when a monorepo has several
+func fragmentPackage(opts *createOptions, cfg *config.Config) (repository.Package, error) {
+	pkgs, err := repository.PackagesFromConfig(cfg)
+	if err != nil {
+		return repository.Package{}, err
+	}
+	if opts.Package != "" {
+		return repository.FindPackage(pkgs, opts.Package)
+	}
+	if len(pkgs) == 1 {
+		return pkgs[0], nil
+	}
+	if opts.prompter == nil {
+		return repository.Package{}, errors.New("--package is required for a fragment in a repository with several packages")
+	}
+
+	names := make([]string, 0, len(pkgs))
+	for _, p := range pkgs {
+		names = append(names, p.Name)
+	}
+	i, err := opts.prompter.Select("Package of the change", names, -1)
+	if err != nil {
+		return repository.Package{}, err
+	}
+	return pkgs[i], nil
+}
+
+// writeFragment writes and stages the fragment so it is part of the commit
+func writeFragment(cfg *config.Config, pkg repository.Package, entry repository.Entry, opts *createOptions) (string, error) {
+	gen, err := repository.GenerateOptionsFromConfig(cfg)
+	if err != nil {
+		return "", err
+	}
+	// links are best effort, a missing or unknown remote only disables them
+	project, _ := git.RemoteProject(git.BaseRemoteName())
+	linker := repository.NewReferenceLinker(project, gen.References)
+
+	path, err := repository.WriteFragment(pkg.FragmentDir(), opts.Type+"-"+opts.Subject, []repository.Entry{entry}, linker)
+	if err != nil {
+		return "", err
+	}
+	if _, err := git.StageFilesForCommit([]string{path}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// isTerminal reports whether answers can be read interactively from in
+func isTerminal(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// changelogTypes returns the commit types listed in the changelog
+func changelogTypes() []string {
+	var types []string
+	for _, t := range repository.CommitTypes() {
+		if _, ok := repository.SectionForType(t); ok {
+			types = append(types, t)
+		}
+	}
+	return types
+}
@@ -0,0 +1,189 @@
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/conventional"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+)
+
+// ErrLintFailed is returned when a commit message breaks a rule
+var ErrLintFailed = errors.New("commit messages do not follow the Conventional Commits rules")
+
+type lintOptions struct {
+	Target string
+	Strict bool
+
+	In  io.Reader
+	Out io.Writer
+}
+
+// Message is a commit message to check, Name identifies it in the output
+type Message struct {
+	Name    string
+	Message string
+}
+
+func newCmdLint() *cobra.Command {
+	opts := &lintOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "lint [<range>|<file>|-]",
+		Short: "Check commit messages against Conventional Commits",
+		Long: `Check commit messages against the Conventional Commits specification and the
+"lint" section of .cliborg.json.
+
+The argument is a revision range such as origin/main..HEAD, a single commit, a
+message file as passed to a commit-msg hook, or - to read a message from
+standard input. Without an argument every commit since the latest tag is
+checked. Merge, revert and fixup commits generated by git are skipped.
+
+Warnings, e.g. for descriptions not in the imperative mood, only fail the
+command with --strict.`,
+		Example: `  cliborg commit lint
+  cliborg commit lint origin/main..HEAD
+  cliborg commit lint .git/COMMIT_EDITMSG
+  echo "feat: add login" | cliborg commit lint -`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Target = args[0]
+			}
+			opts.In = cmd.InOrStdin()
+			opts.Out = cmd.OutOrStdout()
+			return runLint(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Strict, "strict", false, "Fail on warnings too")
+
+	return cmd
+}
+
+func runLint(opts *lintOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	rules := LintRules(cfg.Lint)
+
+	messages, err := lintMessages(opts)
+	if err != nil {
+		return err
+	}
+
+	errorCount, warningCount := Lint(opts.Out, messages, rules)
+	fmt.Fprintf(opts.Out, "Checked %d commit message(s): %d error(s), %d warning(s)\n", len(messages), errorCount, warningCount)
+	if errorCount > 0 || (opts.Strict && warningCount > 0) {
+		return ErrLintFailed
+	}
+	return nil
+}
+
+// Lint checks the messages, prints their problems and returns how many
+// errors and warnings were found
+func Lint(out io.Writer, messages []Message, rules conventional.Rules) (int, int) {
+	errorCount, warningCount := 0, 0
+	for _, m := range messages {
+		problems := conventional.Lint(m.Message, rules)
+		if len(problems) == 0 {
+			continue
+		}
+		fmt.Fprintln(out, m.Name)
+		for _, p := range problems {
+			fmt.Fprintf(out, "    %s\n", p)
+			if p.Severity == conventional.SeverityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+	}
+	return errorCount, warningCount
+}
+
+// LintRules turns the lint configuration into rules, allowing the types
+// known to the changelog when none are configured
+func LintRules(cfg config.Lint) conventional.Rules {
+	rules := conventional.Rules{
+		Types:           cfg.Types,
+		Scopes:          cfg.Scopes,
+		RequireScope:    cfg.RequireScope,
+		MaxHeaderLength: cfg.MaxHeaderLength,
+	}
+	if len(rules.Types) == 0 {
+		rules.Types = repository.CommitTypes()
+	}
+	return rules
+}
+
+// lintMessages reads the messages selected by the target
+func lintMessages(opts *lintOptions) ([]Message, error) {
+	target := opts.Target
+
+	if target == "-" {
+		data, err := io.ReadAll(opts.In)
+		if err != nil {
+			return nil, err
+		}
+		return []Message{{Name: "<stdin>", Message: conventional.StripComments(string(data))}}, nil
+	}
+
+	if target != "" {
+		if data, err := os.ReadFile(target); err == nil {
+			return []Message{{Name: target, Message: conventional.StripComments(string(data))}}, nil
+		}
+	}
+
+	var commits []git.LogEntry
+	switch {
+	case target == "":
+		from, err := git.LatestTag()
+		if err != nil {
+			// no tags yet, check the whole history
+			from = ""
+		}
+		commits, err = git.Log(from, "", false)
+		if err != nil {
+			return nil, err
+		}
+	case strings.Contains(target, "..."):
+		return nil, fmt.Errorf("symmetric ranges are not supported, use <from>..<to>: %s", target)
+	case strings.Contains(target, ".."):
+		from, to, _ := strings.Cut(target, "..")
+		var err error
+		commits, err = git.Log(from, to, false)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		commit, err := git.LastCommit(target)
+		if err != nil {
+			return nil, err
+		}
+		commits = []git.LogEntry{*commit}
+	}
+
+	return CommitMessages(commits), nil
+}
+
+// CommitMessages returns the messages of commits from the log API
+func CommitMessages(commits []git.LogEntry) []Message {
+	messages := make([]Message, 0, len(commits))
+	for _, c := range commits {
+		message := c.Subject
+		if c.Body != "" {
+			message += "\n\n" + c.Body
+		}
+		messages = append(messages, Message{Name: c.ShortHash() + " " + c.Subject, Message: message})
+	}
+	return messages
+}
@@ -0,0 +1,77 @@
+// Package completion implements `cliborg completion`.
+package completion
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Supported shells
+const (
+	shellBash       = "bash"
+	shellZsh        = "zsh"
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+)
+
+// NewCmdCompletion returns the `completion` command
+func NewCmdCompletion() *cobra.Command {
+	var noDescriptions bool
+
+	cmd := &cobra.Command{
+		Use:   "completion <shell>",
+		Short: "Generate the shell completion script",
+		Long: `Generate the completion script for bash, zsh, fish or PowerShell.
+
+Besides commands and flags, branch names, tags, remotes, packages, commit types
+and changelog versions are completed from the repository the shell is in.
+
+Bash (needs the bash-completion package):
+
+  source <(cliborg completion bash)
+  cliborg completion bash > /etc/bash_completion.d/cliborg
+
+Zsh (needs compinit):
+
+  cliborg completion zsh > "${fpath[1]}/_cliborg"
+
+Fish:
+
+  cliborg completion fish > ~/.config/fish/completions/cliborg.fish
+
+PowerShell:
+
+  cliborg completion powershell | Out-String | Invoke-Expression
+
+Start a new shell after installing the script.`,
+		ValidArgs: []string{shellBash, shellZsh, shellFish, shellPowerShell},
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			out := cmd.OutOrStdout()
+
+			switch args[0] {
+			case shellBash:
+				return root.GenBashCompletionV2(out, !noDescriptions)
+			case shellZsh:
+				if noDescriptions {
+					return root.GenZshCompletionNoDesc(out)
+				}
+				return root.GenZshCompletion(out)
+			case shellFish:
+				return root.GenFishCompletion(out, !noDescriptions)
+			case shellPowerShell:
+				if noDescriptions {
+					return root.GenPowerShellCompletion(out)
+				}
+				return root.GenPowerShellCompletionWithDesc(out)
+			}
+			return fmt.Errorf("unsupported shell: %s", args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&noDescriptions, "no-descriptions", false, "Leave out the descriptions of completions")
+
+	return cmd
+}
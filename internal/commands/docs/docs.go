@@ -0,0 +1,88 @@
+// Package docs implements `cliborg docs`.
+package docs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+
+	"github.com/nick-ccc/CLIborg/internal/buildinfo"
+)
+
+// NewCmdDocs returns the `docs` command and its subcommands
+func NewCmdDocs() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docs <command>",
+		Short: "Generate man pages and markdown reference docs",
+		Long: `Generate reference documentation for every cliborg command from the command
+definitions, e.g. when packaging cliborg or publishing its docs.`,
+	}
+
+	cmd.AddCommand(newCmdMan())
+	cmd.AddCommand(newCmdMarkdown())
+
+	return cmd
+}
+
+func newCmdMan() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:     "man",
+		Short:   "Write a man page for every command",
+		Example: `  cliborg docs man --dir /usr/local/share/man/man1`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", dir, err)
+			}
+			root := cmd.Root()
+			// the date would change the pages on every run
+			root.DisableAutoGenTag = true
+			header := &doc.GenManHeader{
+				Title:   "CLIBORG",
+				Section: "1",
+				Source:  "CLIborg " + buildinfo.Current().Version,
+				Manual:  "CLIborg Manual",
+			}
+			if err := doc.GenManTree(root, header, dir); err != nil {
+				return fmt.Errorf("error writing man pages: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Man pages written to %s\n", dir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&dir, "dir", "d", "man", "Directory to write the pages to")
+
+	return cmd
+}
+
+func newCmdMarkdown() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:     "markdown",
+		Short:   "Write a markdown page for every command",
+		Example: `  cliborg docs markdown --dir docs/commands`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", dir, err)
+			}
+			root := cmd.Root()
+			root.DisableAutoGenTag = true
+			if err := doc.GenMarkdownTree(root, dir); err != nil {
+				return fmt.Errorf("error writing markdown docs: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Markdown docs written to %s\n", dir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&dir, "dir", "d", "docs", "Directory to write the pages to")
+
+	return cmd
+}
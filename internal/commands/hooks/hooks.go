@@ -0,0 +1,112 @@
+// Package hooks implements `cliborg hooks`.
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/hooks"
+)
+
+// NewCmdHooks returns the `hooks` command and its subcommands
+func NewCmdHooks() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks <command>",
+		Short: "Manage the git hooks running cliborg checks",
+		Long: `Manage the git hooks running cliborg checks locally:
+
+  commit-msg          lints the commit message
+  prepare-commit-msg  adds a Conventional Commits reminder to the message template
+  pre-push            lints the commits being pushed
+
+Hooks are written to the directory git runs them from, core.hooksPath when
+set. Existing hooks are renamed with a .pre-cliborg suffix and keep running
+before the cliborg check.`,
+	}
+
+	cmd.AddCommand(newCmdInstall())
+	cmd.AddCommand(newCmdUninstall())
+	cmd.AddCommand(newCmdList())
+	cmd.AddCommand(newCmdRun())
+
+	return cmd
+}
+
+func newCmdInstall() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install [<hook>...]",
+		Short: "Install the hooks, all of them by default",
+		Example: `  cliborg hooks install
+  cliborg hooks install commit-msg`,
+		ValidArgs: hooks.Names,
+		Args:      cobra.OnlyValidArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := args
+			if len(names) == 0 {
+				names = hooks.Names
+			}
+
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("could not find the cliborg executable: %w", err)
+			}
+
+			installed, err := hooks.Install(names, executable)
+			for _, h := range installed {
+				fmt.Fprintf(cmd.OutOrStdout(), "Installed %s (%s)\n", h.Path, h.Status)
+			}
+			return err
+		},
+	}
+}
+
+func newCmdUninstall() *cobra.Command {
+	return &cobra.Command{
+		Use:       "uninstall [<hook>...]",
+		Short:     "Remove the hooks, restoring the hooks they ran",
+		ValidArgs: hooks.Names,
+		Args:      cobra.OnlyValidArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := args
+			if len(names) == 0 {
+				names = hooks.Names
+			}
+
+			removed, err := hooks.Uninstall(names)
+			for _, h := range removed {
+				if h.Status == hooks.StatusChained {
+					fmt.Fprintf(cmd.OutOrStdout(), "Removed %s, restored the previous hook\n", h.Path)
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", h.Path)
+				}
+			}
+			if err == nil && len(removed) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No cliborg hooks installed")
+			}
+			return err
+		},
+	}
+}
+
+func newCmdList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Show which hooks are installed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := hooks.List()
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			for _, h := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", h.Name, h.Status, h.Path)
+			}
+			return w.Flush()
+		},
+	}
+}
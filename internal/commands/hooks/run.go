@@ -0,0 +1,215 @@
+package hooks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/commands/commit"
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/conventional"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/hooks"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+)
+
+// zeroSHA is sent by git for refs that do not exist on one side of a push
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+func newCmdRun() *cobra.Command {
+	return &cobra.Command{
+		Use:    "run <hook> [<args>...]",
+		Short:  "Run the check of a hook, called by the installed hook scripts",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		// hook arguments are passed on as is
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			hook, args := args[0], args[1:]
+			switch hook {
+			case hooks.CommitMsg:
+				return runCommitMsg(cmd.ErrOrStderr(), cfg, args)
+			case hooks.PrepareCommitMsg:
+				return runPrepareCommitMsg(cfg, args)
+			case hooks.PrePush:
+				return runPrePush(cmd.InOrStdin(), cmd.ErrOrStderr(), cfg, args)
+			}
+			return fmt.Errorf("unknown hook %q", hook)
+		},
+	}
+}
+
+// runCommitMsg lints the message file git passes to the commit-msg hook
+func runCommitMsg(out io.Writer, cfg *config.Config, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%s expects the message file", hooks.CommitMsg)
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	messages := []commit.Message{{Name: "commit message", Message: conventional.StripComments(string(data))}}
+	return lintForHook(out, messages, cfg)
+}
+
+// runPrepareCommitMsg adds a reminder of the commit format to messages about
+// to be written in an editor, and of changelog fragments when none is staged
+func runPrepareCommitMsg(cfg *config.Config, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%s expects the message file", hooks.PrepareCommitMsg)
+	}
+	// a source means the message came from -m, a template, a merge or a
+	// squash, which are left alone
+	if len(args) > 1 && args[1] != "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	content := string(data)
+
+	// the reminder goes above git's own comments
+	i := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if strings.HasPrefix(line, "#") {
+			break
+		}
+		i += len(line)
+	}
+	reminder := commitReminder()
+	if !fragmentStaged(cfg) {
+		reminder += fragmentReminder
+	}
+	content = content[:i] + reminder + content[i:]
+
+	return os.WriteFile(args[0], []byte(content), 0644)
+}
+
+// commitReminder lists the commit format and the types in the changelog
+func commitReminder() string {
+	bySection := map[string][]string{}
+	for _, t := range repository.CommitTypes() {
+		if section, ok := repository.SectionForType(t); ok {
+			bySection[section] = append(bySection[section], t)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("# Conventional Commit: <type>(<scope>): <description>\n")
+	b.WriteString("# Types listed in the changelog:\n")
+	for _, section := range repository.Sections {
+		if types := bySection[section]; len(types) > 0 {
+			fmt.Fprintf(&b, "#   %-11s %s\n", section, strings.Join(types, ", "))
+		}
+	}
+	b.WriteString("# Breaking changes: <type>!: ... or a \"BREAKING CHANGE: ...\" footer\n")
+	b.WriteString("#\n")
+	return b.String()
+}
+
+// fragmentReminder points feat, fix and breaking commits at fragments, whose
+// entry is used in the changelog instead of the subject
+const fragmentReminder = "# No changelog fragment is staged. feat, fix and breaking commits can\n" +
+	"# describe their change for the changelog with `cliborg commit --fragment`.\n" +
+	"#\n"
+
+// fragmentStaged reports whether a changelog fragment of any package is
+// staged. Errors count as staged, so no reminder is shown.
+func fragmentStaged(cfg *config.Config) bool {
+	pkgs, err := repository.PackagesFromConfig(cfg)
+	if err != nil {
+		return true
+	}
+	files, err := git.StagedFiles()
+	if err != nil {
+		return true
+	}
+	for _, file := range files {
+		for _, pkg := range pkgs {
+			if filepath.Dir(filepath.FromSlash(file)) == filepath.Clean(pkg.FragmentDir()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runPrePush lints the commits of the refs being pushed, which git passes on
+// stdin as "<local ref> <local sha> <remote ref> <remote sha>" lines
+func runPrePush(in io.Reader, out io.Writer, cfg *config.Config, args []string) error {
+	remote := git.BaseRemoteName()
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	var messages []commit.Message
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		localRef, localSHA, remoteSHA := fields[0], fields[1], fields[3]
+		// deletions and tags carry no new commits to lint
+		if localSHA == zeroSHA || strings.HasPrefix(localRef, "refs/tags/") {
+			continue
+		}
+
+		from := remoteSHA
+		if from == zeroSHA || !git.CommitExists(from) {
+			// a new branch is checked against the default branch, as is a
+			// force push over commits that were never fetched
+			from = newBranchBase(remote)
+			if from == "" {
+				continue
+			}
+		}
+
+		commits, err := git.Log(from, localSHA, false)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, commit.CommitMessages(commits)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return lintForHook(out, messages, cfg)
+}
+
+// lintForHook lints quietly unless there are problems, and fails on errors
+func lintForHook(out io.Writer, messages []commit.Message, cfg *config.Config) error {
+	errorCount, _ := commit.Lint(out, messages, commit.LintRules(cfg.Lint))
+	if errorCount > 0 {
+		return fmt.Errorf("%w, fix the message or skip the check with --no-verify", commit.ErrLintFailed)
+	}
+	return nil
+}
+
+// newBranchBase returns the remote-tracking default branch of the remote, or
+// "" when it is unknown
+func newBranchBase(remote string) string {
+	name, err := git.RemoteDefaultBranch(remote)
+	if err != nil {
+		return ""
+	}
+	ref := remote + "/" + name
+	if !git.RefExists("refs/remotes/" + ref) {
+		return ""
+	}
+	return ref
+}
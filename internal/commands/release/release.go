@@ -0,0 +1,411 @@
+// Package release implements `cliborg release`.
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/api"
+	"github.com/nick-ccc/CLIborg/internal/auth"
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+	"github.com/nick-ccc/CLIborg/internal/review"
+	"github.com/nick-ccc/CLIborg/internal/version"
+)
+
+type options struct {
+	Package     string
+	Bump        string
+	Pre         string
+	Cumulative  bool
+	Separate    bool
+	Remote      string
+	PushRemote  string
+	DryRun      bool
+	AllowDirty  bool
+	Push        bool
+	PushOptions []string
+	SkipCI      bool
+	Publish     bool
+	Assets      []string
+	Source      string
+	Interactive bool
+
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+// NewCmdRelease returns the `release` command
+func NewCmdRelease() *cobra.Command {
+	opts := &options{}
+
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Write the changelog, commit and tag the next version",
+		Long: `Work out the next version from the commits since the last release, write its
+changelog file, commit it and tag the commit.
+
+Use --pre to cut a pre-release such as v1.3.0-rc.1. Running release without
+--pre after one or more pre-releases promotes them to the final version and
+consolidates their changelogs into the final release's changelog file.
+
+Changelog fragments written with "cliborg commit --fragment" are added to the
+changelog and removed in the release commit.
+
+Use --interactive to review the entries before the changelog is written:
+reassign sections, rewrite or drop entries and mark breaking changes. The
+review is skipped when stdout is not a terminal.
+
+Use --source merge_requests to build the changelog from the titles, labels,
+authors and numbers of the merge or pull requests merged since the last
+release instead of commit subjects.
+
+Use --publish to create a GitHub or GitLab release for the new tag, picked from
+the remote host and authenticated with the token of that host (see
+"cliborg auth").
+
+Changelog links point at the base remote (see "cliborg repo set-default"),
+while --push and --publish use the remote a plain "git push" of the branch
+goes to, so a release in a fork is not pushed to its upstream. --remote
+sets the remote for all of them.`,
+		Example: `  cliborg release
+  cliborg release --pre rc
+  cliborg release --interactive
+  cliborg release --package svc-a --bump minor --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Out = cmd.OutOrStdout()
+			opts.ErrOut = cmd.ErrOrStderr()
+			if opts.Cumulative && opts.Separate {
+				return errors.New("--cumulative and --separate cannot be used together")
+			}
+			if len(opts.Assets) > 0 && !opts.Publish {
+				return errors.New("--asset requires --publish")
+			}
+			if cmd.Flags().Changed("pre") {
+				if err := version.ValidateChannel(opts.Pre); err != nil {
+					return err
+				}
+			}
+			return runRelease(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Package, "package", "p", "", "Release a single package of a monorepo")
+	cmd.Flags().StringVar(&opts.Bump, "bump", "", "Force the version increment: major, minor or patch")
+	cmd.Flags().StringVar(&opts.Pre, "pre", "", "Cut a pre-release on the given channel, e.g. rc")
+	cmd.Flags().BoolVar(&opts.Cumulative, "cumulative", false, "Pre-release changelog lists every change since the last final release")
+	cmd.Flags().BoolVar(&opts.Separate, "separate", false, "Pre-release changelog lists only the changes since the previous pre-release")
+	cmd.Flags().StringVar(&opts.Remote, "remote", "", "Remote used for links, pushing and publishing, defaults to the base remote for links and the push remote of the branch otherwise")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the changelog without writing, committing or tagging")
+	cmd.Flags().BoolVar(&opts.AllowDirty, "allow-dirty", false, "Release with uncommitted changes in the working tree")
+	cmd.Flags().BoolVar(&opts.Push, "push", false, "Push the release commit and tag")
+	cmd.Flags().BoolVar(&opts.SkipCI, "skip-ci", false, "Mark the release commit to skip CI pipelines")
+	cmd.Flags().StringArrayVarP(&opts.PushOptions, "push-option", "o", nil, "Pass a push option to the server, can be repeated")
+	cmd.Flags().BoolVar(&opts.Publish, "publish", false, "Publish a forge release with the changelog as description, implies --push")
+	cmd.Flags().StringVar(&opts.Source, "source", "", "Build the changelog from commits or merge_requests")
+	cmd.Flags().StringArrayVar(&opts.Assets, "asset", nil, "Upload a file to the published release, can be repeated")
+	cmd.Flags().BoolVarP(&opts.Interactive, "interactive", "i", false, "Review the changelog entries before writing them")
+	_ = cmd.RegisterFlagCompletionFunc("package", completion.Packages)
+	_ = cmd.RegisterFlagCompletionFunc("remote", completion.Remotes)
+	_ = cmd.RegisterFlagCompletionFunc("bump", completion.Fixed("major", "minor", "patch"))
+	_ = cmd.RegisterFlagCompletionFunc("pre", completion.Fixed("alpha", "beta", "rc"))
+	_ = cmd.RegisterFlagCompletionFunc("source", completion.Fixed(config.SourceCommits, config.SourceMergeRequests))
+
+	return cmd
+}
+
+func runRelease(opts *options) error {
+	bump, err := parseBump(opts.Bump)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	pkgs, err := repository.PackagesFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if opts.Package != "" {
+		pkg, err := repository.FindPackage(pkgs, opts.Package)
+		if err != nil {
+			return err
+		}
+		pkgs = []repository.Package{pkg}
+	}
+
+	gen, err := repository.GenerateOptionsFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	// without --remote links point at the base repository while the release
+	// is pushed where a plain `git push` of the branch goes, e.g. a fork
+	opts.PushRemote = opts.Remote
+	if opts.Remote == "" {
+		opts.Remote = git.BaseRemoteName()
+		if (opts.Push || opts.Publish) && !opts.DryRun {
+			branch, err := git.CurrentBranch()
+			if err != nil {
+				return err
+			}
+			opts.PushRemote, err = git.PushRemote(branch)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	gen.Remote = opts.Remote
+	if opts.Source != "" {
+		gen.Source = opts.Source
+	}
+	if gen.Source == config.SourceMergeRequests {
+		project, err := git.RemoteProject(opts.Remote)
+		if err != nil {
+			return err
+		}
+		token, err := auth.TokenFor(project.Host)
+		if err != nil {
+			return err
+		}
+		gen.Forge, err = api.NewForge(project, token)
+		if err != nil {
+			return err
+		}
+	}
+
+	releaseOpts := repository.ReleaseOptions{
+		Bump:       bump,
+		Pre:        opts.Pre,
+		Cumulative: cfg.Changelog.Prerelease == config.PrereleaseCumulative,
+		Generate:   gen,
+	}
+	if opts.Cumulative {
+		releaseOpts.Cumulative = true
+	}
+	if opts.Separate {
+		releaseOpts.Cumulative = false
+	}
+
+	if opts.Interactive {
+		if review.IsTerminal(os.Stdin) && review.IsTerminal(os.Stdout) {
+			releaseOpts.Review = reviewEntries
+		} else {
+			fmt.Fprintln(opts.ErrOut, "stdout is not a terminal, skipping interactive review")
+		}
+	}
+
+	if opts.Publish && !opts.DryRun {
+		// publishing needs a token, fail before anything is committed or pushed
+		project, err := git.RemoteProject(opts.PushRemote)
+		if err != nil {
+			return err
+		}
+		if _, err := auth.Lookup(project.Host); err != nil {
+			return err
+		}
+	}
+
+	if !opts.DryRun && !opts.AllowDirty {
+		count, err := git.UncommittedChangeCount()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("working tree has %d uncommitted changes, commit them or use --allow-dirty", count)
+		}
+	}
+
+	plans, err := repository.PlanReleases(pkgs, releaseOpts)
+	if err != nil {
+		return err
+	}
+	if len(plans) == 0 {
+		return repository.ErrNothingToRelease
+	}
+
+	if (opts.Push || opts.Publish) && !opts.DryRun {
+		// a published tag cannot be pushed again, fail before committing
+		for _, plan := range plans {
+			published, err := git.RemoteTagExists(opts.PushRemote, plan.Tag)
+			if err != nil {
+				return err
+			}
+			if published {
+				return fmt.Errorf("tag %s is already published on %s", plan.Tag, opts.PushRemote)
+			}
+		}
+	}
+
+	commit := cfg.Commit.CommitOptions("")
+	if opts.SkipCI {
+		provider := git.ProviderUnknown
+		if project, err := git.RemoteProject(opts.Remote); err == nil {
+			provider = project.Provider
+		}
+		commit.SkipCIMarker = git.SkipCIMarker(provider, cfg.Commit.SkipCIMarkers)
+	}
+
+	for _, plan := range plans {
+		if err := release(opts, plan, commit); err != nil {
+			return err
+		}
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+	if opts.Push || opts.Publish {
+		if err := push(opts, plans); err != nil {
+			return err
+		}
+	}
+	if opts.Publish {
+		return publish(opts, plans)
+	}
+
+	return nil
+}
+
+func release(opts *options, plan *repository.ReleasePlan, commit git.CommitOptions) error {
+	from := plan.PreviousTag
+	if from == "" {
+		from = "initial release"
+	}
+	fmt.Fprintf(opts.Out, "%s -> %s (%s)\n", from, plan.Tag, plan.Bump)
+	if len(plan.Prereleases) > 0 {
+		fmt.Fprintf(opts.Out, "Consolidating %v\n", plan.Prereleases)
+	}
+
+	if len(plan.Changelog.Fragments) > 0 {
+		fmt.Fprintf(opts.Out, "Consuming fragments %v\n", plan.Changelog.Fragments)
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(opts.Out, "\n%s:\n\n%s\n", plan.ChangelogPath, plan.Changelog)
+		return nil
+	}
+
+	if err := plan.WriteChangelog(); err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.Out, "Changelog file created: %s\n", plan.ChangelogPath)
+	if err := repository.RemoveFragments(plan.Changelog.Fragments); err != nil {
+		return err
+	}
+	if _, err := git.StageFilesForCommit([]string{plan.ChangelogPath}); err != nil {
+		return err
+	}
+	commit.Subject = fmt.Sprintf("chore(release): %s", plan.Tag)
+	if _, err := git.CommitWithOptions(commit); err != nil {
+		return err
+	}
+	if _, err := git.TagRepository(plan.Tag); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func reviewEntries(plan *repository.ReleasePlan) ([]repository.Entry, error) {
+	title := fmt.Sprintf("Review %s (%s)", plan.Tag, plan.ChangelogPath)
+	return review.Run(os.Stdin, os.Stdout, title, plan.Changelog.Entries)
+}
+
+// push updates the branch and the release tags atomically, so a rejected
+// branch never leaves tags behind on the remote
+func push(opts *options, plans []*repository.ReleasePlan) error {
+	branch, err := git.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	refs := []string{"refs/heads/" + branch}
+	for _, plan := range plans {
+		refs = append(refs, "refs/tags/"+plan.Tag)
+	}
+
+	upstream, err := git.GetAllConfig("branch." + branch + ".remote")
+	if err != nil {
+		return err
+	}
+
+	results, err := git.PushRefs(git.PushOptions{
+		Remote:      opts.PushRemote,
+		Refs:        refs,
+		Atomic:      true,
+		SetUpstream: len(upstream) == 0,
+		PushOptions: opts.PushOptions,
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		fmt.Fprintf(opts.Out, "Pushed %s to %s (%s)\n", r.Name(), opts.PushRemote, r.Summary)
+	}
+	return nil
+}
+
+func publish(opts *options, plans []*repository.ReleasePlan) error {
+	project, err := git.RemoteProject(opts.PushRemote)
+	if err != nil {
+		return err
+	}
+
+	token, err := auth.Lookup(project.Host)
+	if err != nil {
+		return err
+	}
+	forge, err := api.NewForge(project, token.Value)
+	if err != nil {
+		return err
+	}
+	uploader, canUpload := forge.(api.AssetUploader)
+	if len(opts.Assets) > 0 && !canUpload {
+		return fmt.Errorf("uploading release assets is not supported for %s", project.Host)
+	}
+
+	ctx := context.Background()
+	for _, plan := range plans {
+		rel, err := forge.CreateRelease(ctx, project.Path, api.ReleaseInput{
+			TagName:     plan.Tag,
+			Name:        plan.Tag,
+			Description: plan.Changelog.Body(),
+		})
+		if err != nil {
+			return err
+		}
+		for _, asset := range opts.Assets {
+			if err := uploader.UploadReleaseAsset(ctx, project.Path, rel, asset); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(opts.Out, "Published %s %s\n", rel.TagName, rel.URL)
+	}
+
+	return nil
+}
+
+func parseBump(s string) (version.Bump, error) {
+	switch s {
+	case "":
+		return version.BumpNone, nil
+	case "major":
+		return version.BumpMajor, nil
+	case "minor":
+		return version.BumpMinor, nil
+	case "patch":
+		return version.BumpPatch, nil
+	}
+	return version.BumpNone, fmt.Errorf("invalid bump %q, expected major, minor or patch", s)
+}
@@ -0,0 +1,254 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/api"
+	"github.com/nick-ccc/CLIborg/internal/auth"
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+const (
+	protocolHTTPS = "https"
+	protocolSSH   = "ssh"
+)
+
+type cloneOptions struct {
+	Repo       string
+	Dir        string
+	GitArgs    []string
+	Hostname   string
+	Protocol   string
+	Depth      int
+	Branch     string
+	Upstream   string
+	NoUpstream bool
+
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+func newCmdClone() *cobra.Command {
+	opts := &cloneOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "clone <repo> [<dir>] [-- <git clone flags>...]",
+		Short: "Clone a repository, adding the upstream of forks",
+		Long: `Clone a repository given as a git URL or as "owner/name".
+
+Local repositories are given as file: URLs, absolute paths or paths starting
+with ./ or ../, so a directory named like a shorthand is not cloned instead.
+
+Shorthands are expanded for --hostname, which defaults to CLIBORG_HOST, the only
+host logged in to with "cliborg auth login", or github.com. A leading host is
+also accepted, e.g. "gitlab.example.com/group/name".
+
+When the forge API reports the repository as a fork, or --upstream is given,
+the parent is added as the upstream remote and picked as the base remote (see
+"cliborg repo set-default"). Otherwise origin is the base remote.`,
+		Example: `  cliborg repo clone owner/name
+  cliborg repo clone gitlab.example.com/group/name --protocol ssh
+  cliborg repo clone me/fork --upstream org/project --depth 1
+  cliborg repo clone owner/name work -- --recurse-submodules`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// arguments after "--" are passed to git clone
+			if n := cmd.ArgsLenAtDash(); n >= 0 {
+				opts.GitArgs = args[n:]
+				args = args[:n]
+			}
+			if len(args) == 0 || len(args) > 2 {
+				return errors.New("expected a repository and an optional directory")
+			}
+			opts.Repo = args[0]
+			if len(args) > 1 {
+				opts.Dir = args[1]
+			}
+
+			if opts.Protocol != protocolHTTPS && opts.Protocol != protocolSSH {
+				return fmt.Errorf("invalid protocol %q, expected https or ssh", opts.Protocol)
+			}
+			if opts.Upstream != "" && opts.NoUpstream {
+				return errors.New("--upstream and --no-upstream cannot be used together")
+			}
+			opts.Out = cmd.OutOrStdout()
+			opts.ErrOut = cmd.ErrOrStderr()
+			return runClone(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Hostname, "hostname", "", "Host to expand owner/name with")
+	cmd.Flags().StringVar(&opts.Protocol, "protocol", protocolHTTPS, "Protocol of expanded URLs: https or ssh")
+	_ = cmd.RegisterFlagCompletionFunc("protocol", completion.Fixed(protocolHTTPS, protocolSSH))
+	cmd.Flags().IntVar(&opts.Depth, "depth", 0, "Create a shallow clone with this many commits")
+	cmd.Flags().StringVarP(&opts.Branch, "branch", "b", "", "Check out this branch instead of the default branch")
+	cmd.Flags().StringVar(&opts.Upstream, "upstream", "", "Add this repository as the upstream remote instead of asking the forge")
+	cmd.Flags().BoolVar(&opts.NoUpstream, "no-upstream", false, "Do not look up and add the parent of a fork")
+
+	return cmd
+}
+
+func runClone(opts *cloneOptions) error {
+	host := opts.Hostname
+	if host == "" {
+		var err error
+		host, err = auth.DefaultHost()
+		if err != nil {
+			return err
+		}
+	}
+
+	cloneURL := opts.Repo
+	var project *git.Project
+	if isLocalRepo(opts.Repo) {
+		// local repositories are cloned as is and never forks
+		opts.NoUpstream = opts.Upstream == ""
+	} else if git.IsValidURL(opts.Repo) {
+		// the project is only needed to look up forks, local paths have none
+		project, _ = git.ProjectFromShorthand(opts.Repo, host)
+	} else {
+		var err error
+		project, err = git.ProjectFromShorthand(opts.Repo, host)
+		if err != nil {
+			return err
+		}
+		cloneURL = cloneURLFor(project, opts.Protocol)
+	}
+
+	upstreamURL, err := resolveUpstream(opts, project, host)
+	if err != nil {
+		return err
+	}
+
+	var cloneArgs []string
+	if opts.Depth > 0 {
+		cloneArgs = append(cloneArgs, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Branch != "" {
+		cloneArgs = append(cloneArgs, "--branch", opts.Branch)
+	}
+	cloneArgs = append(cloneArgs, opts.GitArgs...)
+
+	dir, err := git.RunClone(cloneURL, opts.Dir, cloneArgs)
+	if err != nil {
+		return err
+	}
+
+	base := git.DefaultRemote
+	if upstreamURL != "" {
+		if err := git.AddUpstreamRemote(upstreamURL, dir); err != nil {
+			return fmt.Errorf("adding the upstream remote: %w", err)
+		}
+		base = "upstream"
+	}
+	if err := git.SetBaseRemoteIn(dir, base); err != nil {
+		return err
+	}
+
+	if upstreamURL != "" {
+		fmt.Fprintf(opts.Out, "Cloned into %s, upstream %s is the base remote\n", dir, upstreamURL)
+	} else {
+		fmt.Fprintf(opts.Out, "Cloned into %s\n", dir)
+	}
+	return nil
+}
+
+// resolveUpstream returns the URL of the upstream remote to add, or "" for
+// repositories that are no fork
+func resolveUpstream(opts *cloneOptions, project *git.Project, host string) (string, error) {
+	if opts.NoUpstream {
+		return "", nil
+	}
+	if opts.Upstream != "" {
+		if git.IsValidURL(opts.Upstream) || isLocalRepo(opts.Upstream) {
+			return opts.Upstream, nil
+		}
+		if project != nil {
+			host = project.Scheme + "://" + project.Host
+		}
+		upstream, err := git.ProjectFromShorthand(opts.Upstream, host)
+		if err != nil {
+			return "", err
+		}
+		return cloneURLFor(upstream, opts.Protocol), nil
+	}
+	if project == nil {
+		return "", nil
+	}
+
+	// fork detection is best effort, the clone works without it
+	parent, err := forkParent(project)
+	if errors.Is(err, api.ErrUnsupportedForge) {
+		return "", nil
+	}
+	if err != nil {
+		fmt.Fprintf(opts.ErrOut, "warning: could not check whether %s is a fork: %v\n", project, err)
+		return "", nil
+	}
+	if parent == nil {
+		return "", nil
+	}
+
+	if opts.Protocol == protocolSSH && parent.SSHURL != "" {
+		return parent.SSHURL, nil
+	}
+	if opts.Protocol == protocolHTTPS && parent.HTTPURL != "" {
+		return parent.HTTPURL, nil
+	}
+	upstream := *project
+	upstream.Path = parent.Path
+	return cloneURLFor(&upstream, opts.Protocol), nil
+}
+
+// forkParent asks the forge for the project a repository was forked from
+func forkParent(project *git.Project) (*api.Repository, error) {
+	token, err := auth.TokenFor(project.Host)
+	if err != nil {
+		return nil, err
+	}
+	forge, err := api.NewForge(project, token)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := forge.Repository(context.Background(), project.Path)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Parent, nil
+}
+
+// isLocalRepo reports whether repo is a file URL, an absolute or explicitly
+// relative path, or a repository directory: one with a .git in it or a bare
+// one named *.git. Other arguments are shorthands even when a directory of
+// that name happens to exist.
+func isLocalRepo(repo string) bool {
+	if strings.HasPrefix(repo, "file:") || filepath.IsAbs(repo) {
+		return true
+	}
+	slashed := filepath.ToSlash(repo)
+	if slashed == "." || slashed == ".." || strings.HasPrefix(slashed, "./") || strings.HasPrefix(slashed, "../") {
+		return true
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git")); err == nil {
+		return true
+	}
+	info, err := os.Stat(repo)
+	return err == nil && info.IsDir() && strings.HasSuffix(slashed, ".git")
+}
+
+func cloneURLFor(project *git.Project, protocol string) string {
+	if protocol == protocolSSH {
+		return project.SSHCloneURL()
+	}
+	return project.HTTPSCloneURL()
+}
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsLocalRepo(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"owner/name", "checkout/.git", "bare.git"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+
+	tests := []struct {
+		repo string
+		want bool
+	}{
+		{"owner/name", false},
+		{"other/name", false},
+		{"gitlab.example.com/group/name", false},
+		{"file:///srv/git/name.git", true},
+		{filepath.Join(dir, "owner", "name"), true},
+		{"./owner/name", true},
+		{"../name", true},
+		{".", true},
+		{"checkout", true},
+		{"bare.git", true},
+		{"missing.git", false},
+	}
+	for _, tt := range tests {
+		if got := isLocalRepo(tt.repo); got != tt.want {
+			t.Errorf("isLocalRepo(%q) = %v, want %v", tt.repo, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Package repo implements `cliborg repo`.
+package repo
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCmdRepo returns the `repo` command and its subcommands
+func NewCmdRepo() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repo <command>",
+		Short: "Work with the repository and its remotes",
+	}
+
+	cmd.AddCommand(newCmdClone())
+	cmd.AddCommand(newCmdSetDefault())
+
+	return cmd
+}
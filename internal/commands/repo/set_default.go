@@ -0,0 +1,153 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/prompt"
+)
+
+type setDefaultOptions struct {
+	Remote string
+	View   bool
+	Unset  bool
+
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+func newCmdSetDefault() *cobra.Command {
+	opts := &setDefaultOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "set-default [<remote>]",
+		Short: "Pick the remote of the base repository",
+		Long: `Pick which remote points at the base repository when there are several,
+e.g. a fork as origin and the project it was forked from as upstream.
+
+The choice is stored as remote.<name>.` + git.RemoteResolutionKey + ` in the local git config and
+used by every command that talks to a remote. A remote.<name>.glab-resolved
+choice made with glab is honored too. Without a choice the first of
+upstream, github and origin is used, then the first remote.
+
+Without an argument the remote is picked from a list on a terminal.`,
+		Example: `  cliborg repo set-default upstream
+  cliborg repo set-default --view
+  cliborg repo set-default --unset`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Remotes),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Remote = args[0]
+			}
+			if opts.View && opts.Unset {
+				return errors.New("--view and --unset cannot be used together")
+			}
+			if (opts.View || opts.Unset) && opts.Remote != "" {
+				return errors.New("a remote cannot be given with --view or --unset")
+			}
+			opts.In = cmd.InOrStdin()
+			opts.Out = cmd.OutOrStdout()
+			opts.ErrOut = cmd.ErrOrStderr()
+			return runSetDefault(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.View, "view", false, "Print the base remote")
+	cmd.Flags().BoolVar(&opts.Unset, "unset", false, "Forget the picked remote and use the default order again")
+
+	return cmd
+}
+
+func runSetDefault(opts *setDefaultOptions) error {
+	remotes, err := git.Remotes()
+	if err != nil {
+		return err
+	}
+	if len(remotes) == 0 {
+		return git.ErrNoRemotes
+	}
+
+	switch {
+	case opts.View:
+		base, err := git.BaseRemote()
+		if err != nil {
+			return err
+		}
+		source := "default order"
+		if base.Resolved == git.ResolutionBase {
+			source = "set with `cliborg repo set-default`"
+		}
+		fmt.Fprintf(opts.Out, "%s (%s)\n", describeRemote(base), source)
+		return nil
+
+	case opts.Unset:
+		if err := git.UnsetBaseRemote(); err != nil {
+			return err
+		}
+		fmt.Fprintf(opts.Out, "Base remote unset, using %s\n", git.BaseRemoteName())
+		return nil
+	}
+
+	name := opts.Remote
+	if name == "" {
+		name, err = pickRemote(opts, remotes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := git.SetBaseRemote(name); err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.Out, "Base remote set to %s\n", name)
+	return nil
+}
+
+// pickRemote asks for the base remote on a terminal, preselecting the current one
+func pickRemote(opts *setDefaultOptions, remotes git.RemoteSet) (string, error) {
+	f, ok := opts.In.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return "", errors.New("a remote must be given when not running on a terminal")
+	}
+
+	current := git.BaseRemoteName()
+	labels := make([]string, 0, len(remotes))
+	def := 0
+	for i, r := range remotes {
+		labels = append(labels, describeRemote(r))
+		if r.Name == current {
+			def = i
+		}
+	}
+
+	i, err := prompt.New(opts.In, opts.ErrOut).Select("Which remote points at the base repository?", labels, def)
+	if err != nil {
+		return "", err
+	}
+	return remotes[i].Name, nil
+}
+
+// describeRemote returns the remote name with the project it points at
+func describeRemote(r *git.Remote) string {
+	u := r.FetchURL
+	if u == nil {
+		u = r.PushURL
+	}
+	if u == nil {
+		return r.Name
+	}
+	project, err := git.ProjectFromURL(u)
+	if err != nil {
+		return r.Name
+	}
+	return fmt.Sprintf("%s (%s)", r.Name, project)
+}
@@ -0,0 +1,69 @@
+// Package commands assembles the cliborg command tree.
+package commands
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/buildinfo"
+	"github.com/nick-ccc/CLIborg/internal/commands/auth"
+	"github.com/nick-ccc/CLIborg/internal/commands/branch"
+	"github.com/nick-ccc/CLIborg/internal/commands/changelog"
+	"github.com/nick-ccc/CLIborg/internal/commands/commit"
+	"github.com/nick-ccc/CLIborg/internal/commands/completion"
+	"github.com/nick-ccc/CLIborg/internal/commands/docs"
+	"github.com/nick-ccc/CLIborg/internal/commands/hooks"
+	"github.com/nick-ccc/CLIborg/internal/commands/release"
+	"github.com/nick-ccc/CLIborg/internal/commands/repo"
+	"github.com/nick-ccc/CLIborg/internal/commands/status"
+	"github.com/nick-ccc/CLIborg/internal/commands/tag"
+	"github.com/nick-ccc/CLIborg/internal/commands/version"
+	"github.com/nick-ccc/CLIborg/internal/logging"
+)
+
+// NewCmdRoot returns the top level cliborg command
+func NewCmdRoot() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "cliborg <command> <subcommand> [flags]",
+		Short:         "Release and changelog automation for git repositories",
+		Version:       buildinfo.Current().String(),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.SetVersionTemplate("cliborg {{.Version}}\n")
+
+	logOpts := logging.Options{}
+	cmd.PersistentFlags().BoolVar(&logOpts.Verbose, "verbose", false, "Log every git invocation with its duration and exit code")
+	cmd.PersistentFlags().BoolVar(&logOpts.Trace, "trace", false, "Log like --verbose and add the stderr of successful commands")
+	cmd.PersistentFlags().StringVar(&logOpts.TraceFile, "trace-file", "", "Append a JSON lines trace of the run to `file`, e.g. for bug reports (env "+logging.TraceFileEnv+")")
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		logOpts.ErrOut = cmd.ErrOrStderr()
+		closeLog, err := logging.Setup(logOpts)
+		if err != nil {
+			return err
+		}
+		cobra.OnFinalize(func() {
+			_ = closeLog()
+		})
+		slog.Debug("run", "command", cmd.CommandPath(), "args", args, "version", cmd.Root().Version)
+		return nil
+	}
+	// replaced by the completion command, which documents the installation
+	cmd.CompletionOptions.DisableDefaultCmd = true
+
+	cmd.AddCommand(auth.NewCmdAuth())
+	cmd.AddCommand(branch.NewCmdBranch())
+	cmd.AddCommand(changelog.NewCmdChangelog())
+	cmd.AddCommand(commit.NewCmdCommit())
+	cmd.AddCommand(completion.NewCmdCompletion())
+	cmd.AddCommand(docs.NewCmdDocs())
+	cmd.AddCommand(hooks.NewCmdHooks())
+	cmd.AddCommand(release.NewCmdRelease())
+	cmd.AddCommand(repo.NewCmdRepo())
+	cmd.AddCommand(status.NewCmdStatus())
+	cmd.AddCommand(tag.NewCmdTag())
+	cmd.AddCommand(version.NewCmdVersion())
+
+	return cmd
+}
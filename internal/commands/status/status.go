@@ -0,0 +1,128 @@
+// Package status implements `cliborg status`.
+package status
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// ErrNotInSync is returned by --check when a branch differs from its upstream
+var ErrNotInSync = errors.New("branch is not in sync with its upstream")
+
+type options struct {
+	Branches []string
+	Offline  bool
+	Check    bool
+
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+// NewCmdStatus returns the `status` command
+func NewCmdStatus() *cobra.Command {
+	opts := &options{}
+
+	cmd := &cobra.Command{
+		Use:   "status [<branch>...]",
+		Short: "Show how local branches compare to their upstream",
+		Long: `Show the upstream of each local branch, how many commits it is ahead of and
+behind it, its last commit and whether the upstream branch still exists on the
+remote.
+
+Counts are based on the last fetch. Remotes are asked whether upstream branches
+still exist unless --offline is given. A remote that cannot be reached is
+reported as a warning and its branches keep the state of the last fetch.
+
+With --check the command fails unless the current branch, or every branch
+given, tracks an existing upstream branch and points at the same commit, e.g.
+to verify a release branch before tagging it.`,
+		Example: `  cliborg status
+  cliborg status main --check`,
+		ValidArgsFunction: completion.Branches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Branches = args
+			opts.Out = cmd.OutOrStdout()
+			opts.ErrOut = cmd.ErrOrStderr()
+			return runStatus(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Do not ask the remotes whether upstream branches exist")
+	cmd.Flags().BoolVar(&opts.Check, "check", false, "Fail unless the branches are in sync with their upstream")
+
+	return cmd
+}
+
+func runStatus(opts *options) error {
+	statuses, err := git.BranchStatuses(!opts.Offline)
+	if err != nil {
+		return err
+	}
+
+	wanted := opts.Branches
+	if opts.Check && len(wanted) == 0 {
+		current, err := git.CurrentBranch()
+		if err != nil {
+			return err
+		}
+		wanted = []string{current}
+	}
+	if len(wanted) > 0 {
+		for _, name := range wanted {
+			if !slices.ContainsFunc(statuses, func(s git.TrackingStatus) bool { return s.Name == name }) {
+				return fmt.Errorf("%w: %s", git.ErrBranchNotFound, name)
+			}
+		}
+		statuses = slices.DeleteFunc(statuses, func(s git.TrackingStatus) bool {
+			return !slices.Contains(wanted, s.Name)
+		})
+	}
+
+	w := tabwriter.NewWriter(opts.Out, 0, 4, 2, ' ', 0)
+	for _, s := range statuses {
+		marker := " "
+		if s.Current {
+			marker = "*"
+		}
+		upstream := s.Upstream
+		if upstream == "" {
+			upstream = "-"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s %s (%s)\n", marker, s.Name, upstream, s.Summary(),
+			s.LastCommit.ShortHash(), s.LastCommit.Subject, s.LastCommit.Date.Format("2006-01-02"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// each unreachable remote is reported once
+	var reported []string
+	for _, s := range statuses {
+		if s.RemoteErr != nil && !slices.Contains(reported, s.RemoteErr.Error()) {
+			reported = append(reported, s.RemoteErr.Error())
+			fmt.Fprintf(opts.ErrOut, "Warning: %v\n", s.RemoteErr)
+		}
+	}
+
+	if opts.Check {
+		var notInSync []string
+		for _, s := range statuses {
+			if !s.InSync() {
+				notInSync = append(notInSync, fmt.Sprintf("%s (%s)", s.Name, s.Summary()))
+			}
+		}
+		if len(notInSync) > 0 {
+			return fmt.Errorf("%w: %s", ErrNotInSync, strings.Join(notInSync, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package tag
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+)
+
+// Publication states shown with --remote
+const (
+	statePublished = "published"
+	stateLocal     = "local only"
+	stateRemote    = "remote only"
+	stateDiffers   = "differs"
+)
+
+type listOptions struct {
+	Patterns []string
+	Package  string
+	Remote   string
+	Limit    int
+
+	Out io.Writer
+}
+
+func newCmdList() *cobra.Command {
+	opts := &listOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list [<pattern>...]",
+		Short: "List tags with their type, date and tagger",
+		Long: `List the tags of the repository, highest version first. Tags are sorted by
+semantic version, so v1.10.0 comes after v1.9.0 and pre-releases before their
+final release.
+
+Patterns are globs matched against the full tag name, e.g. "v1.*". Use
+--package to list the tags of a single package of a monorepo.
+
+Use --remote to compare with the tags published on a remote: tags are marked
+as published, only local, only on the remote, or pointing at another commit.`,
+		Example: `  cliborg tag list
+  cliborg tag list "v2.*" --limit 5
+  cliborg tag list --package svc-a --remote origin`,
+		ValidArgsFunction: completion.Tags,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Patterns = args
+			opts.Out = cmd.OutOrStdout()
+			return runList(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Package, "package", "p", "", "List the tags of a single package of a monorepo")
+	cmd.Flags().StringVar(&opts.Remote, "remote", "", "Compare with the tags published on this remote")
+	cmd.Flags().IntVarP(&opts.Limit, "limit", "L", 30, "Maximum number of tags to list, 0 lists every tag")
+	_ = cmd.RegisterFlagCompletionFunc("package", completion.Packages)
+	_ = cmd.RegisterFlagCompletionFunc("remote", completion.Remotes)
+
+	return cmd
+}
+
+func runList(opts *listOptions) error {
+	filter := git.TagFilter{Patterns: opts.Patterns}
+	if opts.Package != "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		pkgs, err := repository.PackagesFromConfig(cfg)
+		if err != nil {
+			return err
+		}
+		pkg, err := repository.FindPackage(pkgs, opts.Package)
+		if err != nil {
+			return err
+		}
+		filter.Prefix = pkg.TagPrefix
+	}
+
+	tags, err := git.Tags(filter)
+	if err != nil {
+		return err
+	}
+
+	states := map[string]string{}
+	if opts.Remote != "" {
+		remoteTags, err := git.RemoteTags(opts.Remote)
+		if err != nil {
+			return err
+		}
+		remoteSHAs := map[string]string{}
+		for _, rt := range remoteTags {
+			if filter.Match(rt.Name) {
+				remoteSHAs[rt.Name] = rt.SHA
+			}
+		}
+		for _, t := range tags {
+			switch sha, ok := remoteSHAs[t.Name]; {
+			case !ok:
+				states[t.Name] = stateLocal
+			case sha != t.SHA:
+				states[t.Name] = stateDiffers
+			default:
+				states[t.Name] = statePublished
+			}
+			delete(remoteSHAs, t.Name)
+		}
+		for name, sha := range remoteSHAs {
+			tags = append(tags, git.Tag{Name: name, SHA: sha})
+			states[name] = stateRemote
+		}
+		git.SortTags(tags)
+	}
+
+	if len(tags) == 0 {
+		fmt.Fprintln(opts.Out, "No tags found")
+		return nil
+	}
+
+	slices.Reverse(tags)
+	if opts.Limit > 0 && len(tags) > opts.Limit {
+		tags = tags[:opts.Limit]
+	}
+
+	w := tabwriter.NewWriter(opts.Out, 0, 4, 2, ' ', 0)
+	for _, t := range tags {
+		date := ""
+		if !t.Date.IsZero() {
+			date = t.Date.Format("2006-01-02")
+		}
+		sha := t.SHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		subject, _, _ := strings.Cut(t.Message, "\n")
+
+		fields := []string{t.Name, string(t.Type), date, sha, t.Tagger, subject}
+		if opts.Remote != "" {
+			fields = append([]string{t.Name, states[t.Name]}, fields[1:]...)
+		}
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+	return w.Flush()
+}
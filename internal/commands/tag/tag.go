@@ -0,0 +1,18 @@
+// Package tag implements `cliborg tag`.
+package tag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCmdTag returns the `tag` command and its subcommands
+func NewCmdTag() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag <command>",
+		Short: "Inspect release tags",
+	}
+
+	cmd.AddCommand(newCmdList())
+
+	return cmd
+}
@@ -0,0 +1,167 @@
+package version
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/completion"
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+)
+
+// Output formats of `version current`
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// describeFlags are the tag selection flags shared by the subcommands
+type describeFlags struct {
+	Package       string
+	Match         []string
+	Exclude       []string
+	Abbrev        int
+	FirstParent   bool
+	AnnotatedOnly bool
+}
+
+type currentOptions struct {
+	describeFlags
+	Always bool
+	Format string
+
+	Out io.Writer
+}
+
+// currentJSON is the JSON output of `version current`
+type currentJSON struct {
+	Version  string `json:"version"`
+	Tag      string `json:"tag,omitempty"`
+	Distance int    `json:"distance"`
+	SHA      string `json:"sha"`
+	Dirty    bool   `json:"dirty"`
+	Exact    bool   `json:"exact"`
+}
+
+func newCmdCurrent() *cobra.Command {
+	opts := &currentOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "current",
+		Short: "Print the version of the checked out commit",
+		Long: `Print the version of the checked out commit, worked out from the nearest
+release tag like git describe.
+
+A tagged commit with a clean working tree prints the tag's version, e.g.
+v1.2.3. Otherwise the pseudo-version of a snapshot build is printed: the
+commits since the tag and the abbreviated hash, followed by -dirty when the
+working tree has changes, e.g. v1.2.3-5-gabc1234-dirty.
+
+Only release tags of the package are considered unless --match is given. Use
+--always to print the hash when no tag is found.`,
+		Example: `  cliborg version current
+  cliborg version current --package svc-a --format json
+  go build -ldflags "-X main.version=$(cliborg version current)"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Out = cmd.OutOrStdout()
+			if opts.Format != FormatText && opts.Format != FormatJSON {
+				return fmt.Errorf("invalid format %q, expected %s or %s", opts.Format, FormatText, FormatJSON)
+			}
+			return runCurrent(opts)
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().BoolVar(&opts.Always, "always", false, "Print the abbreviated hash when no tag is found")
+	cmd.Flags().StringVarP(&opts.Format, "format", "F", FormatText, "Output format: text or json")
+	_ = cmd.RegisterFlagCompletionFunc("format", completion.Fixed(FormatText, FormatJSON))
+
+	return cmd
+}
+
+// addFlags registers the tag selection flags
+func (f *describeFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.Package, "package", "p", "", "Version of a single package of a monorepo")
+	cmd.Flags().StringArrayVar(&f.Match, "match", nil, "Only consider tags matching the glob, can be repeated")
+	cmd.Flags().StringArrayVar(&f.Exclude, "exclude", nil, "Ignore tags matching the glob, can be repeated")
+	cmd.Flags().IntVar(&f.Abbrev, "abbrev", 7, "Length of the abbreviated commit hash")
+	cmd.Flags().BoolVar(&f.FirstParent, "first-parent", false, "Only follow the first parent of merge commits")
+	cmd.Flags().BoolVar(&f.AnnotatedOnly, "annotated", false, "Ignore lightweight tags")
+	_ = cmd.RegisterFlagCompletionFunc("package", completion.Packages)
+}
+
+// describeOptions returns the describe options and the tag prefix of the
+// package. Without --match only the release tags of the package are used.
+func (f *describeFlags) describeOptions() (git.DescribeOptions, string, error) {
+	prefix := ""
+	if f.Package != "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return git.DescribeOptions{}, "", err
+		}
+		pkgs, err := repository.PackagesFromConfig(cfg)
+		if err != nil {
+			return git.DescribeOptions{}, "", err
+		}
+		pkg, err := repository.FindPackage(pkgs, f.Package)
+		if err != nil {
+			return git.DescribeOptions{}, "", err
+		}
+		prefix = pkg.TagPrefix
+	}
+
+	match := f.Match
+	if len(match) == 0 {
+		match = []string{prefix + "v[0-9]*"}
+	}
+
+	return git.DescribeOptions{
+		Match:         match,
+		Exclude:       f.Exclude,
+		Abbrev:        f.Abbrev,
+		FirstParent:   f.FirstParent,
+		AnnotatedOnly: f.AnnotatedOnly,
+	}, prefix, nil
+}
+
+func runCurrent(opts *currentOptions) error {
+	describe, prefix, err := opts.describeOptions()
+	if err != nil {
+		return err
+	}
+	describe.Always = opts.Always
+
+	d, err := git.Describe(describe)
+	if errors.Is(err, git.ErrNoTagsFound) {
+		return fmt.Errorf("%w, use --always to fall back to the commit hash", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the version leaves out the package prefix of the tag
+	version := strings.TrimPrefix(d.String(), prefix)
+
+	if opts.Format == FormatJSON {
+		enc := json.NewEncoder(opts.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(currentJSON{
+			Version:  version,
+			Tag:      d.Tag,
+			Distance: d.Distance,
+			SHA:      d.SHA,
+			Dirty:    d.Dirty,
+			Exact:    d.Exact(),
+		})
+	}
+
+	fmt.Fprintln(opts.Out, version)
+	return nil
+}
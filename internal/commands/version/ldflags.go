@@ -0,0 +1,96 @@
+package version
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/buildinfo"
+)
+
+type ldflagsOptions struct {
+	describeFlags
+	Target    string
+	GoFile    string
+	GoPackage string
+
+	Out io.Writer
+}
+
+func newCmdLDFlags() *cobra.Command {
+	opts := &ldflagsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "ldflags",
+		Short: "Print linker flags stamping the version into a Go binary",
+		Long: `Print -X linker flags setting the version, commit, date and dirty string
+variables of a Go package to the values of the checked out commit.
+
+The version is worked out like "cliborg version current", untagged commits get
+a v0.0.0 pseudo-version. The commit is the full hash, the date the RFC 3339
+committer date and dirty is "true" when the working tree has changes.
+
+The variables must be declared as strings in the target package, "main" by
+default:
+
+  var version, commit, date, dirty string
+
+Use --go-file to generate a Go file declaring the values as constants instead,
+e.g. from a go:generate directive.`,
+		Example: `  go build -ldflags "$(cliborg version ldflags)" ./cmd/app
+  go build -ldflags "$(cliborg version ldflags --target example.com/app/internal/build)"
+  cliborg version ldflags --go-file internal/build/version.go --go-package build`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Out = cmd.OutOrStdout()
+			return runLDFlags(opts)
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().StringVar(&opts.Target, "target", buildinfo.DefaultTarget, "Import path of the package declaring the variables")
+	cmd.Flags().StringVar(&opts.GoFile, "go-file", "", "Write a Go file with the values as constants to this path")
+	cmd.Flags().StringVar(&opts.GoPackage, "go-package", "", "Package name of the generated Go file, defaults to the package of the other Go files in its directory, then the directory name")
+
+	return cmd
+}
+
+func runLDFlags(opts *ldflagsOptions) error {
+	describe, prefix, err := opts.describeOptions()
+	if err != nil {
+		return err
+	}
+
+	info, err := buildinfo.FromGit(describe, prefix)
+	if err != nil {
+		return err
+	}
+
+	if opts.GoFile == "" {
+		fmt.Fprintln(opts.Out, info.LDFlags(opts.Target))
+		return nil
+	}
+
+	pkg := opts.GoPackage
+	if pkg == "" {
+		pkg, err = buildinfo.PackageName(filepath.Dir(opts.GoFile), opts.GoFile)
+		if err != nil {
+			return fmt.Errorf("%w, set the package with --go-package", err)
+		}
+	}
+	src, err := info.GoFile(pkg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.GoFile), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(opts.GoFile), err)
+	}
+	if err := os.WriteFile(opts.GoFile, src, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", opts.GoFile, err)
+	}
+	fmt.Fprintf(opts.Out, "Wrote %s (%s)\n", opts.GoFile, info.Version)
+	return nil
+}
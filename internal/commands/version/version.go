@@ -0,0 +1,19 @@
+// Package version implements `cliborg version`.
+package version
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCmdVersion returns the `version` command and its subcommands
+func NewCmdVersion() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version <command>",
+		Short: "Work out versions for builds",
+	}
+
+	cmd.AddCommand(newCmdCurrent())
+	cmd.AddCommand(newCmdLDFlags())
+
+	return cmd
+}
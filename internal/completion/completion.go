@@ -0,0 +1,141 @@
+// Package completion provides the dynamic shell completions of cliborg
+// commands, read from the repository when a completion is requested.
+package completion
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/repository"
+	"github.com/nick-ccc/CLIborg/internal/version"
+)
+
+// Fixed completes one of the given values
+func Fixed(values ...string) cobra.CompletionFunc {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// FirstArg only completes the first positional argument with fn
+func FirstArg(fn cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// Branches completes local branch names
+func Branches(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	branches, err := git.LocalBranches()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(branches))
+	for _, b := range branches {
+		names = append(names, b.Name)
+	}
+	return unused(names, args), cobra.ShellCompDirectiveNoFileComp
+}
+
+// Tags completes tag names, highest version first
+func Tags(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	tags, err := git.ListTags()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	slices.Reverse(tags)
+	return unused(tags, args), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// Remotes completes remote names with the host and project they point at
+func Remotes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	remotes, err := git.Remotes()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []cobra.Completion
+	for _, r := range remotes {
+		u := r.FetchURL
+		if u == nil {
+			u = r.PushURL
+		}
+		if u == nil {
+			completions = append(completions, r.Name)
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(r.Name, u.Host+u.Path))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Packages completes the package names of a monorepo
+func Packages(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(cfg.Packages))
+	for _, p := range cfg.Packages {
+		names = append(names, p.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// CommitTypes completes the conventional commit types, the configured lint
+// types when set
+func CommitTypes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err == nil && len(cfg.Lint.Types) > 0 {
+		return cfg.Lint.Types, cobra.ShellCompDirectiveNoFileComp
+	}
+	return repository.CommitTypes(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// Scopes completes the scopes allowed by the lint configuration
+func Scopes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return cfg.Lint.Scopes, cobra.ShellCompDirectiveNoFileComp
+}
+
+// ChangelogVersions completes the versions that have a changelog file,
+// highest first, with the tag prefix of their package
+func ChangelogVersions(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	pkgs, err := repository.PackagesFromConfig(cfg)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var tags []string
+	for _, pkg := range pkgs {
+		paths, _ := filepath.Glob(filepath.Join(pkg.ChangelogDir, "CHANGELOG-*.md"))
+		for _, path := range paths {
+			name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "CHANGELOG-"), ".md")
+			if v, err := version.Parse(name); err == nil {
+				tags = append(tags, pkg.Tag(v))
+			}
+		}
+	}
+	git.SortTagNames(tags)
+	slices.Reverse(tags)
+	return tags, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// unused leaves out the values already given as arguments
+func unused(values, args []string) []string {
+	return slices.DeleteFunc(values, func(v string) bool {
+		return slices.Contains(args, v)
+	})
+}
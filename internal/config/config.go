@@ -0,0 +1,183 @@
+// Package config loads the per-repository CLIborg configuration file.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// FileName is the configuration file looked up at the repository root
+const FileName = ".cliborg.json"
+
+// DefaultChangelogDir is where changelog files are written when not configured
+const DefaultChangelogDir = "changelogs"
+
+// Config is the repository level configuration
+type Config struct {
+	Changelog Changelog `json:"changelog"`
+	Commit    Commit    `json:"commit"`
+	Lint      Lint      `json:"lint"`
+	// Packages splits a monorepo into independently versioned packages
+	Packages []Package `json:"packages,omitempty"`
+}
+
+// Changelog configures changelog generation
+type Changelog struct {
+	Dir   string `json:"dir,omitempty"`
+	Image string `json:"image,omitempty"`
+	// References replaces the default issue/merge request patterns when set
+	References []Reference `json:"references,omitempty"`
+	// GroupByReference nests entries under the issue they reference
+	GroupByReference bool `json:"groupByReference,omitempty"`
+	// Prerelease is either PrereleaseSeparate or PrereleaseCumulative
+	Prerelease string `json:"prerelease,omitempty"`
+	// Source is either SourceCommits or SourceMergeRequests
+	Source string `json:"source,omitempty"`
+	// Labels maps merge request labels to changelog sections, in addition
+	// to the default labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// ExcludeLabels leaves out merge requests carrying any of these labels,
+	// in addition to the default skip-changelog
+	ExcludeLabels []string `json:"excludeLabels,omitempty"`
+}
+
+// Commit configures the commits created by cliborg
+type Commit struct {
+	// SkipCIMarkers overrides the marker skipping CI per provider, e.g.
+	// {"gitlab": "[ci skip]"}
+	SkipCIMarkers map[string]string `json:"skipCIMarkers,omitempty"`
+	// SignOff adds a Signed-off-by trailer
+	SignOff bool `json:"signOff,omitempty"`
+	// Sign is "openpgp", "ssh" or "none", empty follows commit.gpgSign
+	Sign       string `json:"sign,omitempty"`
+	SigningKey string `json:"signingKey,omitempty"`
+}
+
+// Lint configures `cliborg commit lint`
+type Lint struct {
+	// Types defaults to the types known to the changelog
+	Types []string `json:"types,omitempty"`
+	// Scopes allows any scope when empty
+	Scopes       []string `json:"scopes,omitempty"`
+	RequireScope bool     `json:"requireScope,omitempty"`
+	// MaxHeaderLength defaults to 72, -1 disables the check
+	MaxHeaderLength int `json:"maxHeaderLength,omitempty"`
+}
+
+// Changelog sources
+const (
+	// SourceCommits builds entries from commit subjects
+	SourceCommits = "commits"
+	// SourceMergeRequests builds entries from merged merge/pull requests
+	SourceMergeRequests = "merge_requests"
+)
+
+// CommitOptions returns the options for a commit with the given subject
+func (c Commit) CommitOptions(subject string) git.CommitOptions {
+	return git.CommitOptions{
+		Subject:    subject,
+		SignOff:    c.SignOff,
+		Sign:       git.SignMode(c.Sign),
+		SigningKey: c.SigningKey,
+	}
+}
+
+// Pre-release changelog modes
+const (
+	// PrereleaseSeparate lists only the changes since the previous pre-release
+	PrereleaseSeparate = "separate"
+	// PrereleaseCumulative lists every change since the last final release
+	PrereleaseCumulative = "cumulative"
+)
+
+// Reference describes how to detect and link a reference in commit subjects.
+// Pattern is a regular expression whose first capture group is the id, and
+// URL may contain "{id}" to override the link derived from the remote.
+type Reference struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// Package is an independently released part of a monorepo
+type Package struct {
+	Name string `json:"name"`
+	// Paths are globs of the files owned by the package, "**" matches any
+	// number of directories
+	Paths []string `json:"paths"`
+	// TagPrefix is prepended to versions, e.g. "svc-a/" gives "svc-a/v1.2.0"
+	TagPrefix string `json:"tagPrefix,omitempty"`
+	// ChangelogDir defaults to the changelog dir joined with the package name
+	ChangelogDir string `json:"changelogDir,omitempty"`
+}
+
+// Default returns the configuration used when no file is present
+func Default() *Config {
+	return &Config{
+		Changelog: Changelog{
+			Dir:        DefaultChangelogDir,
+			Prerelease: PrereleaseSeparate,
+			Source:     SourceCommits,
+		},
+	}
+}
+
+// Load reads the configuration file from the repository root, falling back
+// to defaults when the file does not exist
+func Load() (*Config, error) {
+	root, err := git.ToplevelDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not find repository root: %w", err)
+	}
+	return LoadFile(filepath.Join(root, FileName))
+}
+
+// LoadFile reads the configuration from the given path
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	if cfg.Changelog.Dir == "" {
+		cfg.Changelog.Dir = DefaultChangelogDir
+	}
+
+	switch cfg.Changelog.Source {
+	case "":
+		cfg.Changelog.Source = SourceCommits
+	case SourceCommits, SourceMergeRequests:
+	default:
+		return nil, fmt.Errorf("invalid changelog source %q in %s", cfg.Changelog.Source, path)
+	}
+
+	switch cfg.Changelog.Prerelease {
+	case "":
+		cfg.Changelog.Prerelease = PrereleaseSeparate
+	case PrereleaseSeparate, PrereleaseCumulative:
+	default:
+		return nil, fmt.Errorf("invalid changelog prerelease mode %q in %s", cfg.Changelog.Prerelease, path)
+	}
+
+	switch git.SignMode(cfg.Commit.Sign) {
+	case git.SignDefault, git.SignGPG, git.SignSSH, git.SignNone:
+	default:
+		return nil, fmt.Errorf("invalid commit sign mode %q in %s", cfg.Commit.Sign, path)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,94 @@
+// Package conventional parses commit messages following the Conventional
+// Commits specification (https://www.conventionalcommits.org).
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// headerRE matches `type(scope)!: description`
+var headerRE = regexp.MustCompile(`^(\w+)(?:\(([^()]*)\))?(!)?:\s*(.*)$`)
+
+// footerRE matches git trailer style footers and `BREAKING CHANGE: ...`
+var footerRE = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[\w-]+)(?::\s+(.*)|\s(#.*))$`)
+
+// Footer is a single `Token: value` line at the end of the commit body
+type Footer struct {
+	Token string
+	Value string
+}
+
+// Message is a parsed commit message
+type Message struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+	Body        string
+	Footers     []Footer
+	// Conventional is false when the header did not follow the spec, in which
+	// case Description holds the full subject
+	Conventional bool
+}
+
+// Parse splits a commit subject and body into a Message
+func Parse(subject, body string) Message {
+	msg := Message{Description: strings.TrimSpace(subject)}
+
+	if m := headerRE.FindStringSubmatch(msg.Description); m != nil {
+		msg.Type = strings.ToLower(m[1])
+		msg.Scope = m[2]
+		msg.Breaking = m[3] == "!"
+		msg.Description = strings.TrimSpace(m[4])
+		msg.Conventional = true
+	}
+
+	msg.Body, msg.Footers = splitFooters(body)
+	for _, f := range msg.Footers {
+		if f.Token == "BREAKING CHANGE" || f.Token == "BREAKING-CHANGE" {
+			msg.Breaking = true
+		}
+	}
+
+	return msg
+}
+
+// Footer returns the value of the first footer with the given token
+func (m Message) Footer(token string) (string, bool) {
+	for _, f := range m.Footers {
+		if strings.EqualFold(f.Token, token) {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// splitFooters separates the trailing footer paragraph from the body
+func splitFooters(body string) (string, []Footer) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", nil
+	}
+
+	paragraphs := strings.Split(body, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+
+	var footers []Footer
+	for line := range strings.SplitSeq(last, "\n") {
+		m := footerRE.FindStringSubmatch(line)
+		if m == nil {
+			// continuation of the previous footer value
+			if len(footers) > 0 && strings.TrimSpace(line) != "" {
+				footers[len(footers)-1].Value += "\n" + strings.TrimSpace(line)
+				continue
+			}
+			// not a footer paragraph
+			return body, nil
+		}
+		footers = append(footers, Footer{Token: m[1], Value: strings.TrimSpace(m[2] + m[3])})
+	}
+
+	rest := strings.Join(paragraphs[:len(paragraphs)-1], "\n\n")
+	return strings.TrimSpace(rest), footers
+}
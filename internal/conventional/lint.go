@@ -0,0 +1,216 @@
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxHeaderLength is the header length allowed when not configured
+const DefaultMaxHeaderLength = 72
+
+// Severity of a lint problem
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a rule violated by a commit message
+type Problem struct {
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s (%s)", p.Severity, p.Message, p.Rule)
+}
+
+// Rules configures Lint
+type Rules struct {
+	// Types and Scopes restrict the allowed values, nil allows any
+	Types  []string
+	Scopes []string
+	// RequireScope rejects headers without a scope
+	RequireScope bool
+	// MaxHeaderLength limits the header, 0 uses DefaultMaxHeaderLength and a
+	// negative value disables the check
+	MaxHeaderLength int
+}
+
+// skippedRE matches messages generated by git that are not linted
+var skippedRE = regexp.MustCompile(`^(Merge |Revert "|(fixup|squash|amend)! )`)
+
+// footerLikeRE matches lines that look like footers but may be malformed
+var footerLikeRE = regexp.MustCompile(`^([A-Za-z][\w -]*?)(:|\s#)`)
+
+// breakingLikeRE matches any spelling of a breaking change footer
+var breakingLikeRE = regexp.MustCompile(`^(?i)breaking[ -]change`)
+
+// nonImperativeRE matches description starts like "added", "fixes", "adding"
+var nonImperativeRE = regexp.MustCompile(`^(?i)[a-z]+(ed|es|ing)$`)
+
+// imperativeExceptions end like past tense or gerunds but are imperative
+var imperativeExceptions = []string{"bring", "embed", "exceed", "feed", "need", "ping", "proceed", "seed", "shed", "speed", "spring", "string"}
+
+// Skipped reports whether a commit subject is generated by git, e.g. merges,
+// reverts and fixups, and is not expected to follow the spec
+func Skipped(subject string) bool {
+	return skippedRE.MatchString(subject)
+}
+
+// Lint checks a full commit message against the Conventional Commits spec
+// and the rules
+func Lint(message string, rules Rules) []Problem {
+	subject, body, _ := strings.Cut(message, "\n")
+	subject = strings.TrimRight(subject, " \t")
+
+	if strings.TrimSpace(subject) == "" {
+		return []Problem{{Rule: "header-empty", Severity: SeverityError, Message: "the commit message is empty"}}
+	}
+	if Skipped(subject) {
+		return nil
+	}
+
+	var problems []Problem
+	add := func(rule string, severity Severity, format string, args ...any) {
+		problems = append(problems, Problem{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	maxLength := rules.MaxHeaderLength
+	if maxLength == 0 {
+		maxLength = DefaultMaxHeaderLength
+	}
+	if n := utf8.RuneCountInString(subject); maxLength > 0 && n > maxLength {
+		add("header-max-length", SeverityError, "the header is %d characters long, at most %d are allowed", n, maxLength)
+	}
+
+	if body != "" && !strings.HasPrefix(body, "\n") {
+		add("body-leading-blank", SeverityError, "the body must be separated from the header by a blank line")
+	}
+
+	idx := headerRE.FindStringSubmatchIndex(subject)
+	if idx == nil {
+		add("header-format", SeverityError, "the header must look like \"type(scope): description\"")
+		return problems
+	}
+	m := headerRE.FindStringSubmatch(subject)
+	typ, scope, description := m[1], m[2], m[4]
+
+	if typ != strings.ToLower(typ) {
+		add("type-case", SeverityError, "the type %q must be lower case", typ)
+	}
+	if len(rules.Types) > 0 && !slices.Contains(rules.Types, strings.ToLower(typ)) {
+		add("type-enum", SeverityError, "the type %q is not one of %s", typ, strings.Join(rules.Types, ", "))
+	}
+
+	switch {
+	case idx[4] != -1 && idx[4] == idx[5]:
+		add("scope-empty", SeverityError, "the scope must not be empty, leave out the parentheses instead")
+	case scope == "" && rules.RequireScope:
+		add("scope-required", SeverityError, "a scope is required")
+	case scope != "" && len(rules.Scopes) > 0 && !slices.Contains(rules.Scopes, scope):
+		add("scope-enum", SeverityError, "the scope %q is not one of %s", scope, strings.Join(rules.Scopes, ", "))
+	}
+
+	// the description group starts after the colon and its whitespace
+	switch {
+	case description == "":
+		add("description-empty", SeverityError, "the description must not be empty")
+	case subject[idx[8]-1] != ' ':
+		add("description-space", SeverityError, "a space must follow the colon")
+	}
+	if strings.HasSuffix(description, ".") {
+		add("description-full-stop", SeverityError, "the description must not end with a full stop")
+	}
+	if first, _ := utf8.DecodeRuneInString(description); unicode.IsUpper(first) && !isAcronym(description) {
+		add("description-case", SeverityWarning, "the description should start with a lower case letter")
+	}
+	if word := firstWord(description); nonImperativeRE.MatchString(word) && !slices.Contains(imperativeExceptions, strings.ToLower(word)) {
+		add("description-imperative", SeverityWarning, "the description should use the imperative mood (\"add\", not \"added\"), found %q", word)
+	}
+
+	problems = append(problems, lintFooters(body)...)
+	return problems
+}
+
+// lintFooters checks the last paragraph of the body when it holds footers
+func lintFooters(body string) []Problem {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil
+	}
+	paragraphs := strings.Split(body, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+
+	// only paragraphs holding at least one valid footer are footer paragraphs
+	lines := strings.Split(last, "\n")
+	if !slices.ContainsFunc(lines, func(line string) bool {
+		return footerRE.MatchString(line) || breakingLikeRE.MatchString(line)
+	}) {
+		return nil
+	}
+
+	var problems []Problem
+	for _, line := range lines {
+		m := footerLikeRE.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		token := m[1]
+
+		if strings.EqualFold(token, "BREAKING CHANGE") || strings.EqualFold(token, "BREAKING-CHANGE") {
+			if !footerRE.MatchString(line) || token != strings.ToUpper(token) {
+				problems = append(problems, Problem{
+					Rule:     "footer-breaking-change",
+					Severity: SeverityError,
+					Message:  "breaking changes must be written as \"BREAKING CHANGE: description\"",
+				})
+			}
+			continue
+		}
+
+		// a token with spaces in a footer paragraph is a malformed footer
+		if strings.Contains(token, " ") && !footerRE.MatchString(line) {
+			problems = append(problems, Problem{
+				Rule:     "footer-token",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("footer token %q must use \"-\" instead of spaces", token),
+			})
+		}
+	}
+	return problems
+}
+
+// StripComments drops git's comment lines and the diff below a scissors
+// line, as found in the message file passed to a commit-msg hook
+func StripComments(message string) string {
+	var lines []string
+	for line := range strings.SplitSeq(message, "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimLeft(strings.Join(lines, "\n"), "\n")
+}
+
+func firstWord(s string) string {
+	word, _, _ := strings.Cut(s, " ")
+	return strings.TrimRight(word, ",:;")
+}
+
+// isAcronym reports whether the description starts with an all caps word
+// such as "API" or "README"
+func isAcronym(s string) bool {
+	word := firstWord(s)
+	return utf8.RuneCountInString(word) > 1 && strings.ToUpper(word) == word
+}
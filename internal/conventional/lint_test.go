@@ -0,0 +1,55 @@
+package conventional
+
+import (
+	"slices"
+	"testing"
+)
+
+func rulesOf(problems []Problem) []string {
+	var rules []string
+	for _, p := range problems {
+		rules = append(rules, p.Rule)
+	}
+	return rules
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		rules   Rules
+		want    []string
+	}{
+		{name: "valid", message: "feat(api): add endpoint", want: nil},
+		{name: "parentheses in description", message: "fix: handle nil in Parse()", want: nil},
+		{name: "empty scope", message: "feat(): x", want: []string{"scope-empty"}},
+		{name: "scope", message: "feat(api): x", want: nil},
+		{name: "empty message", message: "", want: []string{"header-empty"}},
+		{name: "merge skipped", message: "Merge branch 'main' into feat", want: nil},
+		{name: "no type", message: "add endpoint", want: []string{"header-format"}},
+		{name: "upper case type", message: "Feat: add endpoint", want: []string{"type-case"}},
+		{name: "type enum", message: "wip: add endpoint", rules: Rules{Types: []string{"feat", "fix"}}, want: []string{"type-enum"}},
+		{name: "scope required", message: "feat: add endpoint", rules: Rules{RequireScope: true}, want: []string{"scope-required"}},
+		{name: "scope enum", message: "feat(db): add endpoint", rules: Rules{Scopes: []string{"api"}}, want: []string{"scope-enum"}},
+		{name: "missing space", message: "feat:add endpoint", want: []string{"description-space"}},
+		{name: "empty description", message: "feat: ", want: []string{"description-empty"}},
+		{name: "full stop", message: "feat: add endpoint.", want: []string{"description-full-stop"}},
+		{name: "upper case description", message: "feat: Add endpoint", want: []string{"description-case"}},
+		{name: "acronym", message: "feat: API endpoint", want: nil},
+		{name: "past tense", message: "feat: added endpoint", want: []string{"description-imperative"}},
+		{name: "imperative exception", message: "feat: embed assets", want: nil},
+		{name: "header too long", message: "feat: add endpoint", rules: Rules{MaxHeaderLength: 10}, want: []string{"header-max-length"}},
+		{name: "length check disabled", message: "feat: add endpoint", rules: Rules{MaxHeaderLength: -1}, want: nil},
+		{name: "body without blank line", message: "feat: add endpoint\nbody", want: []string{"body-leading-blank"}},
+		{name: "breaking footer", message: "feat!: add endpoint\n\nBREAKING CHANGE: removes v1", want: nil},
+		{name: "malformed breaking footer", message: "feat: add endpoint\n\nBreaking change: removes v1", want: []string{"footer-breaking-change"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rulesOf(Lint(tt.message, tt.rules))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Lint(%q) = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
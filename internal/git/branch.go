@@ -0,0 +1,211 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+var (
+	// ErrBranchExists indicates that a branch to create already exists
+	ErrBranchExists = errors.New("branch already exists")
+	// ErrBranchNotFound indicates that a local branch does not exist
+	ErrBranchNotFound = errors.New("branch not found")
+	// ErrBranchNotMerged indicates that deleting a branch would lose commits
+	ErrBranchNotMerged = errors.New("branch is not fully merged")
+	// ErrCurrentBranch indicates an attempt to delete the checked out branch
+	ErrCurrentBranch = errors.New("cannot delete the checked out branch")
+	// ErrDefaultBranch indicates an attempt to delete the default branch
+	ErrDefaultBranch = errors.New("cannot delete the default branch")
+)
+
+// Branch is a local branch
+type Branch struct {
+	Name string
+	Hash string
+	// Upstream is the tracked branch, e.g. "origin/main", "" when untracked
+	Upstream string
+	// Gone reports that the upstream branch was deleted on the remote
+	Gone    bool
+	Current bool
+}
+
+// LocalBranches lists the local branches sorted by name
+func LocalBranches() ([]Branch, error) {
+	listCmd := GitCommand("for-each-ref",
+		"--format=%(refname:short)%00%(objectname)%00%(upstream:short)%00%(upstream:track)%00%(HEAD)",
+		"refs/heads")
+	output, err := run.PrepareCmd(listCmd).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing branches: %w", err)
+	}
+
+	var branches []Branch
+	for _, line := range outputLines(output) {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 5 {
+			continue
+		}
+		branches = append(branches, Branch{
+			Name:     fields[0],
+			Hash:     fields[1],
+			Upstream: fields[2],
+			Gone:     fields[3] == "[gone]",
+			Current:  fields[4] == "*",
+		})
+	}
+	return branches, nil
+}
+
+// BranchExists reports whether a local branch exists
+func BranchExists(name string) bool {
+	return RefExists("refs/heads/" + name)
+}
+
+// RefExists reports whether a fully qualified ref exists
+func RefExists(ref string) bool {
+	verifyCmd := GitCommand("rev-parse", "--verify", "--quiet", ref)
+	_, err := run.PrepareCmd(verifyCmd).Output()
+	return err == nil
+}
+
+// CommitExists reports whether the commit is in the local object store, e.g.
+// before logging from a SHA a remote reported
+func CommitExists(sha string) bool {
+	catCmd := GitCommand("cat-file", "-e", sha+"^{commit}")
+	_, err := run.PrepareCmd(catCmd).Output()
+	return err == nil
+}
+
+// CreateBranch creates a branch at base, HEAD when empty, and optionally
+// switches to it. A branch started from a remote-tracking ref does not track
+// it, its upstream is set when it is first pushed.
+func CreateBranch(name, base string, checkout bool) error {
+	if BranchExists(name) {
+		return fmt.Errorf("%w: %s", ErrBranchExists, name)
+	}
+	if base == "" {
+		base = "HEAD"
+	}
+
+	args := []string{"branch", name, base}
+	if checkout {
+		args = []string{"checkout", "-b", name, base}
+	}
+	if isRemoteTrackingRef(base) {
+		args = slices.Insert(args, 1, "--no-track")
+	}
+	createCmd := GitCommand(args...)
+	_, err := run.PrepareCmd(createCmd).Output()
+	if err != nil {
+		return fmt.Errorf("could not create branch %s from %s: %w", name, base, err)
+	}
+	return nil
+}
+
+// isRemoteTrackingRef reports whether ref names a remote-tracking branch,
+// e.g. "origin/main", rather than a local branch
+func isRemoteTrackingRef(ref string) bool {
+	if strings.HasPrefix(ref, "refs/remotes/") {
+		return true
+	}
+	return !BranchExists(ref) && RefExists("refs/remotes/"+ref)
+}
+
+// RemoteDefaultBranch returns the default branch of a remote as recorded by
+// its HEAD ref, falling back to a local main or master branch
+func RemoteDefaultBranch(remote string) (string, error) {
+	refCmd := GitCommand("symbolic-ref", "--quiet", "--short", "refs/remotes/"+remote+"/HEAD")
+	output, err := run.PrepareCmd(refCmd).Output()
+	if err == nil {
+		return strings.TrimPrefix(firstLine(output), remote+"/"), nil
+	}
+
+	for _, name := range []string{"main", "master"} {
+		if BranchExists(name) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine the default branch of %s, run `git remote set-head %s --auto`", remote, remote)
+}
+
+// IsMerged reports whether every commit of branch is reachable from into
+func IsMerged(branch, into string) (bool, error) {
+	ancestorCmd := GitCommand("merge-base", "--is-ancestor", branch, into)
+	_, err := run.PrepareCmd(ancestorCmd).Output()
+	if err == nil {
+		return true, nil
+	}
+
+	// exit status 1 without output means "not an ancestor"
+	var cmdErr *run.CmdError
+	if errors.As(err, &cmdErr) && cmdErr.Stderr.Len() == 0 {
+		return false, nil
+	}
+	return false, fmt.Errorf("checking whether %s is merged into %s: %w", branch, into, err)
+}
+
+// MergedBranches lists the local branches merged into a ref
+func MergedBranches(into string) ([]string, error) {
+	listCmd := GitCommand("for-each-ref", "--merged", into, "--format=%(refname:short)", "refs/heads")
+	output, err := run.PrepareCmd(listCmd).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing branches merged into %s: %w", into, err)
+	}
+
+	var names []string
+	for _, line := range outputLines(output) {
+		if line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// DeleteBranch deletes a local branch. The checked out branch is never
+// deleted, and branches not merged into the into ref only when forced.
+func DeleteBranch(name, into string, force bool) error {
+	branches, err := LocalBranches()
+	if err != nil {
+		return err
+	}
+
+	var branch *Branch
+	for i := range branches {
+		if branches[i].Name == name {
+			branch = &branches[i]
+		}
+	}
+	if branch == nil {
+		return fmt.Errorf("%w: %s", ErrBranchNotFound, name)
+	}
+	if branch.Current {
+		return fmt.Errorf("%w: %s, switch to another branch first", ErrCurrentBranch, name)
+	}
+
+	if !force {
+		merged, err := IsMerged(name, into)
+		if err != nil {
+			return err
+		}
+		if !merged {
+			return fmt.Errorf("%w: %s has commits missing from %s, use --force to delete it anyway", ErrBranchNotMerged, name, into)
+		}
+	}
+
+	return DeleteLocalBranch(name)
+}
+
+// FetchPrune fetches a remote, dropping remote-tracking refs of branches
+// deleted on the remote
+func FetchPrune(remote string) error {
+	fetchCmd := GitCommand("fetch", "--prune", "--quiet", remote)
+	_, err := run.PrepareCmd(fetchCmd).Output()
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", remote, err)
+	}
+	return nil
+}
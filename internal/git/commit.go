@@ -0,0 +1,185 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+// ErrNothingToCommit indicates that a commit would have no changes
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+// Common commit trailer tokens
+const (
+	TrailerSignedOffBy   = "Signed-off-by"
+	TrailerCoAuthoredBy  = "Co-authored-by"
+	TrailerChangelogType = "Changelog-Type"
+)
+
+// DefaultSkipCIMarker is recognised by GitHub Actions, GitLab CI, Bitbucket
+// Pipelines and Woodpecker
+const DefaultSkipCIMarker = "[skip ci]"
+
+// SkipCIMarkers are the markers used per provider when not configured
+var SkipCIMarkers = map[Provider]string{
+	ProviderGitHub:    DefaultSkipCIMarker,
+	ProviderGitLab:    DefaultSkipCIMarker,
+	ProviderBitbucket: DefaultSkipCIMarker,
+	ProviderGitea:     DefaultSkipCIMarker,
+}
+
+// SkipCIMarker returns the marker skipping CI for a provider, overrides are
+// keyed by provider name, e.g. {"gitlab": "[ci skip]"}
+func SkipCIMarker(provider Provider, overrides map[string]string) string {
+	if marker, ok := overrides[string(provider)]; ok && marker != "" {
+		return marker
+	}
+	if marker, ok := SkipCIMarkers[provider]; ok {
+		return marker
+	}
+	return DefaultSkipCIMarker
+}
+
+// Trailer is a "Token: value" line at the end of a commit message
+type Trailer struct {
+	Token string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Token + ": " + t.Value
+}
+
+// SignMode selects how a commit is signed
+type SignMode string
+
+const (
+	// SignDefault follows the commit.gpgSign setting
+	SignDefault SignMode = ""
+	SignGPG     SignMode = "openpgp"
+	SignSSH     SignMode = "ssh"
+	SignNone    SignMode = "none"
+)
+
+// CommitOptions configures CommitWithOptions
+type CommitOptions struct {
+	Subject string
+	// Body holds the paragraphs after the subject, separated by blank lines
+	Body     string
+	Trailers []Trailer
+	// SignOff adds a Signed-off-by trailer for the committer
+	SignOff bool
+	// SkipCIMarker is appended to the subject when set, see SkipCIMarker
+	SkipCIMarker string
+	Sign         SignMode
+	// SigningKey overrides user.signingKey for SignGPG and SignSSH
+	SigningKey string
+	// Amend replaces HEAD, keeping its message when Subject is empty
+	Amend      bool
+	AllowEmpty bool
+	// All stages modified and deleted tracked files first
+	All bool
+	// Author overrides the author as "Name <email>", Date the author date
+	Author string
+	Date   time.Time
+}
+
+// Message returns the full commit message without trailers
+func (o CommitOptions) Message() string {
+	subject := strings.TrimSpace(o.Subject)
+	if o.SkipCIMarker != "" && !strings.Contains(subject, o.SkipCIMarker) {
+		subject += " " + o.SkipCIMarker
+	}
+	if body := strings.TrimSpace(o.Body); body != "" {
+		return subject + "\n\n" + body + "\n"
+	}
+	return subject + "\n"
+}
+
+// CommitWithOptions records the staged changes and returns the hash of the
+// new commit. The message is passed on stdin so bodies keep their formatting.
+func CommitWithOptions(opts CommitOptions) (string, error) {
+	if strings.TrimSpace(opts.Subject) == "" && !opts.Amend {
+		return "", errors.New("a commit subject is required")
+	}
+
+	var args []string
+	switch opts.Sign {
+	case SignGPG, SignSSH:
+		args = append(args, "-c", "gpg.format="+string(opts.Sign))
+	}
+	// only whitespace is cleaned up, lines starting with "#" are references
+	args = append(args, "commit", "--quiet", "--cleanup=whitespace")
+
+	var stdin *strings.Reader
+	if strings.TrimSpace(opts.Subject) == "" {
+		args = append(args, "--no-edit")
+	} else {
+		args = append(args, "--file=-")
+		stdin = strings.NewReader(opts.Message())
+	}
+
+	for _, t := range opts.Trailers {
+		args = append(args, "--trailer", t.String())
+	}
+	if opts.SignOff {
+		args = append(args, "--signoff")
+	}
+	switch opts.Sign {
+	case SignGPG, SignSSH:
+		if opts.SigningKey != "" {
+			args = append(args, "--gpg-sign="+opts.SigningKey)
+		} else {
+			args = append(args, "--gpg-sign")
+		}
+	case SignNone:
+		args = append(args, "--no-gpg-sign")
+	}
+	if opts.Amend {
+		args = append(args, "--amend")
+	}
+	if opts.AllowEmpty {
+		args = append(args, "--allow-empty")
+	}
+	if opts.All {
+		args = append(args, "--all")
+	}
+	if opts.Author != "" {
+		args = append(args, "--author="+opts.Author)
+	}
+	if !opts.Date.IsZero() {
+		args = append(args, "--date="+opts.Date.Format(time.RFC3339))
+	}
+
+	commitCmd := GitCommand(args...)
+	if stdin != nil {
+		commitCmd.Stdin = stdin
+	}
+	output, err := run.PrepareCmd(commitCmd).Output()
+	if err != nil {
+		// git reports an empty commit on stdout and exits with 1
+		var cmdErr *run.CmdError
+		if errors.As(err, &cmdErr) && cmdErr.Stderr.Len() == 0 {
+			if strings.Contains(string(output), "nothing to commit") || strings.Contains(string(output), "no changes added to commit") {
+				return "", ErrNothingToCommit
+			}
+			return "", ErrCommitFailed
+		}
+		return "", fmt.Errorf("%w: %w", ErrCommitFailed, err)
+	}
+
+	return HeadSHA()
+}
+
+// HeadSHA returns the full hash of the checked out commit
+func HeadSHA() (string, error) {
+	revCmd := GitCommand("rev-parse", "HEAD")
+	output, err := run.PrepareCmd(revCmd).Output()
+	if err != nil {
+		return "", fmt.Errorf("reading HEAD: %w", err)
+	}
+	return firstLine(output), nil
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+// ErrNoCredential indicates that no credential helper had a credential
+var ErrNoCredential = errors.New("no credential found")
+
+// Credential is a username/password pair returned by a credential helper
+type Credential struct {
+	Username string
+	Password string
+}
+
+// CredentialFill asks the configured git credential helpers for the
+// credential of a host without ever prompting the user.
+// Reference: https://git-scm.com/docs/git-credential
+func CredentialFill(protocol, host string) (*Credential, error) {
+	fillCmd := GitCommand("credential", "fill")
+	fillCmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\n\n", protocol, host))
+	// never fall back to an interactive prompt
+	fillCmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+
+	output, err := run.PrepareCmd(fillCmd).Output()
+	if err != nil {
+		var cmdErr *run.CmdError
+		if errors.As(err, &cmdErr) {
+			return nil, ErrNoCredential
+		}
+		return nil, err
+	}
+
+	cred := &Credential{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "username":
+			cred.Username = value
+		case "password":
+			cred.Password = value
+		}
+	}
+
+	if cred.Password == "" {
+		return nil, ErrNoCredential
+	}
+	return cred, nil
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+// ErrNoTagsFound indicates that no tag describes the commit
+var ErrNoTagsFound = errors.New("no tags can describe the commit")
+
+// dirtyMark is appended by git describe when the working tree has changes
+const dirtyMark = "-dirty"
+
+// describeRE splits `git describe --long` output into tag, distance and
+// abbreviated hash
+var describeRE = regexp.MustCompile(`^(.+)-(\d+)-g([0-9a-f]+)$`)
+
+// DescribeOptions configures Describe
+type DescribeOptions struct {
+	// Ref is the commit to describe, HEAD when empty. The dirty flag is only
+	// reported for HEAD.
+	Ref string
+	// Match and Exclude are glob patterns for the tag names to consider
+	Match   []string
+	Exclude []string
+	// Abbrev is the length of the abbreviated hash, 0 uses the git default
+	Abbrev int
+	// FirstParent only follows the first parent of merge commits
+	FirstParent bool
+	// Always falls back to the abbreviated hash when no tag matches
+	Always bool
+	// AnnotatedOnly ignores lightweight tags
+	AnnotatedOnly bool
+}
+
+// Description is a commit described relative to the nearest tag
+type Description struct {
+	// Tag is empty when the commit was described by its hash alone
+	Tag string
+	// Distance is the number of commits since the tag
+	Distance int
+	// SHA is the abbreviated hash of the commit
+	SHA   string
+	Dirty bool
+}
+
+// Exact reports whether the commit is tagged and the tree is clean
+func (d Description) Exact() bool {
+	return d.Tag != "" && d.Distance == 0 && !d.Dirty
+}
+
+// String formats the description like git describe: the tag alone when the
+// commit is tagged, otherwise "<tag>-<distance>-g<sha>", followed by "-dirty"
+// for a modified working tree, e.g. v1.2.3-5-gabc123-dirty. It doubles as
+// the pseudo-version of snapshot builds.
+func (d Description) String() string {
+	var s string
+	switch {
+	case d.Tag == "":
+		s = d.SHA
+	case d.Distance == 0:
+		s = d.Tag
+	default:
+		s = fmt.Sprintf("%s-%d-g%s", d.Tag, d.Distance, d.SHA)
+	}
+	if d.Dirty {
+		s += dirtyMark
+	}
+	return s
+}
+
+// Describe describes a commit by the nearest reachable tag
+func Describe(opts DescribeOptions) (*Description, error) {
+	args := []string{"describe", "--long"}
+	if !opts.AnnotatedOnly {
+		args = append(args, "--tags")
+	}
+	for _, m := range opts.Match {
+		args = append(args, "--match="+m)
+	}
+	for _, e := range opts.Exclude {
+		args = append(args, "--exclude="+e)
+	}
+	if opts.Abbrev > 0 {
+		args = append(args, "--abbrev="+strconv.Itoa(opts.Abbrev))
+	}
+	if opts.FirstParent {
+		args = append(args, "--first-parent")
+	}
+	if opts.Always {
+		args = append(args, "--always")
+	}
+	if opts.Ref == "" || opts.Ref == "HEAD" {
+		args = append(args, "--dirty="+dirtyMark)
+	} else {
+		args = append(args, opts.Ref)
+	}
+
+	describeCmd := GitCommand(args...)
+	output, err := run.PrepareCmd(describeCmd).Output()
+	if err != nil {
+		var cmdErr *run.CmdError
+		if errors.As(err, &cmdErr) {
+			stderr := cmdErr.Stderr.String()
+			if strings.Contains(stderr, "No names found") || strings.Contains(stderr, "tags can describe") {
+				return nil, ErrNoTagsFound
+			}
+		}
+		return nil, fmt.Errorf("describing %s: %w", describeRef(opts.Ref), err)
+	}
+
+	return parseDescribe(firstLine(output))
+}
+
+func parseDescribe(s string) (*Description, error) {
+	d := &Description{}
+	if rest, ok := strings.CutSuffix(s, dirtyMark); ok {
+		d.Dirty = true
+		s = rest
+	}
+
+	m := describeRE.FindStringSubmatch(s)
+	if m == nil {
+		// --always printed the hash alone
+		d.SHA = s
+		return d, nil
+	}
+
+	distance, err := strconv.Atoi(m[2])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected git describe output: %q", s)
+	}
+	d.Tag = m[1]
+	d.Distance = distance
+	d.SHA = m[3]
+	return d, nil
+}
+
+func describeRef(ref string) string {
+	if ref == "" {
+		return "HEAD"
+	}
+	return ref
+}
@@ -79,9 +79,9 @@ func CurrentBranch() (string, error) {
 	return "", err
 }
 
-// Checks if branch exists and returns T/F
-func RemoteBranchExists(branch string) (bool, error) {
-	refCmd := GitCommand("ls-remote", "--exit-code", "--heads", DefaultRemote, branch)
+// RemoteBranchExists asks the remote whether it has the branch
+func RemoteBranchExists(remote, branch string) (bool, error) {
+	refCmd := GitCommand("ls-remote", "--exit-code", "--heads", remote, "refs/heads/"+branch)
 
 	_, err := run.PrepareCmd(refCmd).Output()
 	if err == nil {
@@ -89,16 +89,30 @@ func RemoteBranchExists(branch string) (bool, error) {
 		return true, nil
 	}
 
-	var cmdErr *run.CmdError
-	if errors.As(err, &cmdErr) {
-		if cmdErr.Stderr.Len() == 0 {
-			// Detached head
-			return false, ErrNotOnAnyBranch
-		}
+	// --exit-code exits with 2 when nothing matched, the remote may still
+	// print warnings to stderr
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+		return false, nil
+	}
+	return false, fmt.Errorf("checking for branch %s on %s: %w", branch, remote, err)
+}
+
+// RemoteBranches lists the branches of a remote
+func RemoteBranches(remote string) ([]string, error) {
+	lsCmd := GitCommand("ls-remote", "--heads", remote)
+	output, err := run.PrepareCmd(lsCmd).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing branches on %s: %w", remote, err)
 	}
 
-	// Unknown error
-	return false, err
+	var branches []string
+	for _, line := range outputLines(output) {
+		if _, ref, ok := strings.Cut(line, "\t"); ok {
+			branches = append(branches, strings.TrimPrefix(ref, "refs/heads/"))
+		}
+	}
+	return branches, nil
 }
 
 func ParseDefaultBranch(output []byte) (string, error) {
@@ -187,28 +201,35 @@ func SetUpstream(remote string, branch string, cmdOut, cmdErr io.Writer) error {
 	return run.PrepareCmd(setCmd).Run()
 }
 
+// DeleteLocalBranch force deletes a local branch, see DeleteBranch for the
+// checked variant
 func DeleteLocalBranch(branch string) error {
 	branchCMD := GitCommand("branch", "-D", branch)
 	err := run.PrepareCmd(branchCMD).Run()
 	if err != nil {
-		return fmt.Errorf("could not checkout branch: %w", err)
+		return fmt.Errorf("could not delete branch %s: %w", branch, err)
 	}
 	return nil
 }
 
+// CheckoutBranch switches to an existing branch
 func CheckoutBranch(branch string) error {
 	branchCMD := GitCommand("checkout", branch)
 	err := run.PrepareCmd(branchCMD).Run()
 	if err != nil {
-		return fmt.Errorf("could not checkout branch: %w", err)
+		return fmt.Errorf("could not checkout branch %s: %w", branch, err)
 	}
 	return nil
 }
 
+// CheckoutNewBranch creates a branch at HEAD and switches to it
 func CheckoutNewBranch(branch string) error {
 	branchCMD := GitCommand("checkout", "-b", branch)
 	err := run.PrepareCmd(branchCMD).Run()
-	return err
+	if err != nil {
+		return fmt.Errorf("could not create branch %s: %w", branch, err)
+	}
+	return nil
 }
 
 func RunClone(cloneURL string, target string, args []string) (string, error) {
@@ -293,8 +314,17 @@ func Remotes() (RemoteSet, error) {
 	}
 	remotes := parseRemotes(list)
 
-	// this is affected by SetRemoteResolution
-	remoteCmd := exec.Command("git", "config", "--get-regexp", `^remote\..*\.glab-resolved$`)
+	// this is affected by SetRemoteResolution, marks written by glab are
+	// read first so that cliborg's own take precedence
+	readRemoteResolutions(remotes, legacyRemoteResolutionKey)
+	readRemoteResolutions(remotes, RemoteResolutionKey)
+
+	return remotes, nil
+}
+
+// readRemoteResolutions sets Resolved from the remote config key
+func readRemoteResolutions(remotes RemoteSet, key string) {
+	remoteCmd := exec.Command("git", "config", "--get-regexp", `^remote\..*\.`+regexp.QuoteMeta(key)+`$`)
 	output, _ := run.PrepareCmd(remoteCmd).Output()
 	for _, l := range outputLines(output) {
 		parts := strings.SplitN(l, " ", 2)
@@ -313,8 +343,6 @@ func Remotes() (RemoteSet, error) {
 			}
 		}
 	}
-
-	return remotes, nil
 }
 
 func parseRemotes(gitRemotes []string) RemoteSet {
@@ -386,7 +414,7 @@ func AddRemote(name, u string) (*Remote, error) {
 }
 
 var SetRemoteResolution = func(name, resolution string) error {
-	return SetRemoteConfig(name, "glab-resolved", resolution)
+	return SetRemoteConfig(name, RemoteResolutionKey, resolution)
 }
 
 func SetRemoteConfig(remote, key, value string) error {
@@ -441,6 +469,45 @@ func GetAllConfig(key string) ([]byte, error) {
 	return nil, fmt.Errorf("getting Git configuration value cmd: %s: %w", gitCmd.String(), err)
 }
 
+// GetConfig returns the value git uses for a config key, the last one when it
+// is set several times, e.g. globally and locally, and "" when it is unset
+func GetConfig(key string) (string, error) {
+	err := assertValidConfigKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	gitCmd := GitCommand("config", "--get", key)
+	output, err := run.PrepareCmd(gitCmd).Output()
+	if err == nil {
+		return firstLine(output), nil
+	}
+
+	// git-config exits with 1 without output when the key is not set
+	var cmdErr *run.CmdError
+	if errors.As(err, &cmdErr) && cmdErr.Stderr.Len() == 0 {
+		return "", nil
+	}
+	return "", fmt.Errorf("getting Git configuration value cmd: %s: %w", gitCmd.String(), err)
+}
+
+// PushRemote returns the remote a plain `git push` of the branch goes to:
+// branch.<name>.pushRemote, remote.pushDefault, branch.<name>.remote and
+// finally origin
+func PushRemote(branch string) (string, error) {
+	for _, key := range []string{"branch." + branch + ".pushRemote", "remote.pushDefault", "branch." + branch + ".remote"} {
+		remote, err := GetConfig(key)
+		if err != nil {
+			return "", err
+		}
+		// "." is the local repository of branches tracking a local branch
+		if remote != "" && remote != "." {
+			return remote, nil
+		}
+	}
+	return DefaultRemote, nil
+}
+
 func assertValidConfigKey(key string) error {
 	s := strings.Split(key, ".")
 	if len(s) < 2 {
@@ -463,8 +530,9 @@ func RunCmd(args []string) error {
 	return run.PrepareCmd(gitCmd).Run()
 }
 
-// DescribeByTags gives a description of the current object.
-// Non-annotated tags are considered.
+// DescribeByTags describes HEAD by the nearest tag, lightweight tags
+// included, e.g. "v1.2.3" or "v1.2.3-5-gabc123". Use Describe for a parsed
+// result and dirty working trees.
 // Reference: https://git-scm.com/docs/git-describe
 func DescribeByTags() (string, error) {
 	gitCmd := GitCommand("describe", "--tags")
@@ -474,10 +542,11 @@ func DescribeByTags() (string, error) {
 		return "", fmt.Errorf("running cmd: %s out: %s: %w", gitCmd.String(), output, err)
 	}
 
-	return string(output), nil
+	return firstLine(output), nil
 }
 
-// ListTags gives a slice of tags from the current repository.
+// ListTags returns the tag names of the current repository in ascending
+// version order, see Tags for their metadata
 func ListTags() ([]string, error) {
 	gitCmd := GitCommand("tag", "-l")
 
@@ -491,7 +560,9 @@ func ListTags() ([]string, error) {
 		return nil, nil
 	}
 
-	return strings.Fields(tagsStr), nil
+	tags := strings.Fields(tagsStr)
+	SortTagNames(tags)
+	return tags, nil
 }
 
 // Checks if branch exists and returns T/F
@@ -518,62 +589,63 @@ func StageFilesForCommit(files []string) (bool, error) {
 	return false, err
 }
 
-// Commits staged changes T/F
-func Commit(message string, noCI bool) (bool, error) {
-	if noCI {
-		message = message + " [no CI]"
+// RemoveFiles deletes tracked files from the working tree and stages their
+// removal, files git does not track are ignored
+func RemoveFiles(files []string) error {
+	rmArgs := append([]string{"rm", "--quiet", "--force", "--ignore-unmatch", "--"}, files...)
+	rmCmd := GitCommand(rmArgs...)
+	_, err := run.PrepareCmd(rmCmd).Output()
+	if err != nil {
+		return fmt.Errorf("could not remove %s: %w", strings.Join(files, ", "), err)
 	}
+	return nil
+}
 
-	commitCMD := GitCommand("commit", "-m", message)
-	output, err := run.PrepareCmd(commitCMD).Output()
-	if err == nil {
-		// Remote Branch
-		fmt.Println(outputLines(output))
-		return true, nil
+// StagedFiles lists the files added, copied, modified or renamed in the index,
+// relative to the top-level directory
+func StagedFiles() ([]string, error) {
+	diffCmd := GitCommand("diff", "--cached", "--name-only", "--diff-filter=ACMR")
+	output, err := run.PrepareCmd(diffCmd).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing staged files: %w", err)
 	}
 
-	var cmdErr *run.CmdError
-	if errors.As(err, &cmdErr) {
-		if cmdErr.Stderr.Len() == 0 {
-			// Detached head
-			return false, ErrCommitFailed
+	var files []string
+	for _, line := range outputLines(output) {
+		if line != "" {
+			files = append(files, line)
 		}
 	}
-
-	// Unknown error
-	return false, err
+	return files, nil
 }
 
-// Commits and stages all tracked files T/F
-func StageAndCommitTracked(message string) (bool, error) {
-
-	commitCMD := GitCommand("commit", "-am", message)
-	output, err := run.PrepareCmd(commitCMD).Output()
-	if err == nil {
-		// Remote Branch
-		fmt.Println(outputLines(output))
-		return true, nil
+// Commit records the staged changes, see CommitWithOptions for more control
+func Commit(message string, noCI bool) (bool, error) {
+	subject, body, _ := strings.Cut(message, "\n")
+	opts := CommitOptions{Subject: subject, Body: body}
+	if noCI {
+		opts.SkipCIMarker = DefaultSkipCIMarker
 	}
-
-	var cmdErr *run.CmdError
-	if errors.As(err, &cmdErr) {
-		if cmdErr.Stderr.Len() == 0 {
-			// Detached head
-			return false, ErrCommitFailed
-		}
+	if _, err := CommitWithOptions(opts); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	// Unknown error
-	return false, err
+// StageAndCommitTracked commits every modified tracked file
+func StageAndCommitTracked(message string) (bool, error) {
+	subject, body, _ := strings.Cut(message, "\n")
+	if _, err := CommitWithOptions(CommitOptions{Subject: subject, Body: body, All: true}); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // Tag repository
 func TagRepository(tagName string) (bool, error) {
 	tagCMD := GitCommand("tag", tagName)
-	output, err := run.PrepareCmd(tagCMD).Output()
+	_, err := run.PrepareCmd(tagCMD).Output()
 	if err == nil {
-		// Remote Branch
-		fmt.Println(outputLines(output))
 		return true, nil
 	}
 
@@ -590,27 +662,13 @@ func TagRepository(tagName string) (bool, error) {
 
 }
 
-// Push publishes a git ref to a remote
+// Push publishes a git ref to a remote, see PushRefs for more control
 func Push(remote string, ref string) (bool, error) {
-	pushCmd := GitCommand("push", remote, ref)
-
-	_, err := run.PrepareCmd(pushCmd).Output()
-	if err == nil {
-		// Remote Branch
-		fmt.Printf("Successfully Pushed, %s:%s\n", remote, ref)
-		return true, nil
-	}
-
-	var cmdErr *run.CmdError
-	if errors.As(err, &cmdErr) {
-		if cmdErr.Stderr.Len() == 0 {
-			// Detached head
-			return false, ErrPushFailed
-		}
+	_, err := PushRefs(PushOptions{Remote: remote, Refs: []string{ref}})
+	if err != nil {
+		return false, err
 	}
-
-	// Unknown error
-	return false, err
+	return true, nil
 }
 
 func LogChanges() ([]string, error) {
@@ -632,3 +690,15 @@ func LogChanges() ([]string, error) {
 	// Unknown error
 	return []string{}, err
 }
+
+// LatestTag returns the most recent tag reachable from HEAD
+func LatestTag() (string, error) {
+	gitCmd := GitCommand("describe", "--tags", "--abbrev=0")
+
+	output, err := run.PrepareCmd(gitCmd).Output()
+	if err != nil {
+		return "", fmt.Errorf("running cmd: %s out: %s: %w", gitCmd.String(), output, err)
+	}
+
+	return firstLine(output), nil
+}
@@ -0,0 +1,21 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+// HooksDir returns the directory git runs hooks from. git resolves it,
+// honoring core.hooksPath as git itself reads it, i.e. the last value when it
+// is set at several levels, and the hooks directory shared by worktrees.
+func HooksDir() (string, error) {
+	pathCmd := GitCommand("rev-parse", "--git-path", "hooks")
+	output, err := run.PrepareCmd(pathCmd).Output()
+	if err != nil {
+		return "", fmt.Errorf("could not find the hooks directory: %w", err)
+	}
+	// relative to the current directory, not the top-level directory
+	return filepath.Abs(firstLine(output))
+}
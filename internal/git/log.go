@@ -0,0 +1,139 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+// Field and record separators used to split `git log` output
+const (
+	logFieldSep  = "\x1f"
+	logRecordSep = "\x1e"
+)
+
+// logFormat is the pretty format used by Log, fields are separated by
+// logFieldSep and each commit starts with logRecordSep
+const logFormat = "%x1e%H%x1f%an%x1f%ae%x1f%aI%x1f%s%x1f%b%x1f"
+
+// LogEntry is a single parsed commit from the git log
+type LogEntry struct {
+	Hash        string
+	AuthorName  string
+	AuthorEmail string
+	Date        time.Time
+	Subject     string
+	Body        string
+	// Files is only populated when the log was requested with file names
+	Files []string
+}
+
+// ShortHash returns the abbreviated commit hash
+func (e LogEntry) ShortHash() string {
+	if len(e.Hash) > 7 {
+		return e.Hash[:7]
+	}
+	return e.Hash
+}
+
+// Log returns the commits reachable from `to` but not from `from`, newest first.
+// An empty `from` walks the full history and an empty `to` defaults to HEAD.
+func Log(from, to string, withFiles bool) ([]LogEntry, error) {
+	args := []string{"-c", "log.ShowSignature=false", "log", "--format=" + logFormat}
+	if withFiles {
+		args = append(args, "--name-only")
+	}
+	args = append(args, revisionRange(from, to))
+
+	logCmd := GitCommand(args...)
+	output, err := run.PrepareCmd(logCmd).Output()
+	if err == nil {
+		return parseLog(string(output)), nil
+	}
+
+	var cmdErr *run.CmdError
+	if errors.As(err, &cmdErr) {
+		if cmdErr.Stderr.Len() == 0 {
+			// Detached head
+			return nil, ErrNotOnAnyBranch
+		}
+	}
+
+	// Unknown error
+	return nil, err
+}
+
+// LastCommit returns the commit a ref points at
+func LastCommit(ref string) (*LogEntry, error) {
+	logCmd := GitCommand("-c", "log.ShowSignature=false", "log", "-1", "--format="+logFormat, ref, "--")
+	output, err := run.PrepareCmd(logCmd).Output()
+	if err != nil {
+		return nil, fmt.Errorf("reading commit of %s: %w", ref, err)
+	}
+
+	entries := parseLog(string(output))
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no commit for %s", ref)
+	}
+	return &entries[0], nil
+}
+
+func revisionRange(from, to string) string {
+	if to == "" {
+		to = "HEAD"
+	}
+	if from == "" {
+		return to
+	}
+	return from + ".." + to
+}
+
+func parseLog(output string) []LogEntry {
+	var entries []LogEntry
+
+	for record := range strings.SplitSeq(output, logRecordSep) {
+		fields := strings.Split(record, logFieldSep)
+		if len(fields) < 7 {
+			continue
+		}
+
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		entry := LogEntry{
+			Hash:        fields[0],
+			AuthorName:  fields[1],
+			AuthorEmail: fields[2],
+			Date:        date,
+			Subject:     fields[4],
+			Body:        strings.TrimSpace(fields[5]),
+		}
+
+		for f := range strings.SplitSeq(fields[6], "\n") {
+			f = strings.TrimSpace(f)
+			if f != "" {
+				entry.Files = append(entry.Files, f)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// CommitDate returns the committer date of the commit a ref points at, which
+// unlike the author date changes on rebase
+func CommitDate(ref string) (time.Time, error) {
+	logCmd := GitCommand("-c", "log.ShowSignature=false", "log", "-1", "--format=%cI", ref, "--")
+	output, err := run.PrepareCmd(logCmd).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading commit date of %s: %w", ref, err)
+	}
+	date, err := time.Parse(time.RFC3339, firstLine(output))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse the commit date of %s: %w", ref, err)
+	}
+	return date, nil
+}
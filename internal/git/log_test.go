@@ -0,0 +1,84 @@
+package git
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logRecord formats a commit like logFormat, files are appended the way
+// --name-only prints them
+func logRecord(hash, name, email, date, subject, body string, files ...string) string {
+	record := logRecordSep + strings.Join([]string{hash, name, email, date, subject, body, ""}, logFieldSep)
+	if len(files) > 0 {
+		record += "\n\n" + strings.Join(files, "\n") + "\n"
+	}
+	return record
+}
+
+func TestParseLog(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []LogEntry
+	}{
+		{
+			name:   "empty",
+			output: "",
+			want:   nil,
+		},
+		{
+			name: "commits",
+			output: logRecord("aaa", "Ann", "ann@example.com", "2025-01-02T10:00:00+01:00", "feat: add x", "") + "\n" +
+				logRecord("bbb", "Bob", "bob@example.com", "2025-01-01T10:00:00Z", "fix: y", "Longer text.\n\nRefs: #1\n") + "\n",
+			want: []LogEntry{
+				{Hash: "aaa", AuthorName: "Ann", AuthorEmail: "ann@example.com", Date: time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC), Subject: "feat: add x"},
+				{Hash: "bbb", AuthorName: "Bob", AuthorEmail: "bob@example.com", Date: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Subject: "fix: y", Body: "Longer text.\n\nRefs: #1"},
+			},
+		},
+		{
+			name:   "files",
+			output: logRecord("aaa", "Ann", "ann@example.com", "2025-01-02T10:00:00Z", "feat: add x", "", "a.go", "pkg/b.go"),
+			want: []LogEntry{
+				{Hash: "aaa", AuthorName: "Ann", AuthorEmail: "ann@example.com", Date: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), Subject: "feat: add x", Files: []string{"a.go", "pkg/b.go"}},
+			},
+		},
+		{
+			name:   "separators in the body",
+			output: logRecord("aaa", "Ann", "ann@example.com", "2025-01-02T10:00:00Z", "chore: x", "a: b\n- c"),
+			want: []LogEntry{
+				{Hash: "aaa", AuthorName: "Ann", AuthorEmail: "ann@example.com", Date: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), Subject: "chore: x", Body: "a: b\n- c"},
+			},
+		},
+		{
+			name:   "truncated record",
+			output: logRecordSep + "aaa" + logFieldSep + "Ann",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLog(tt.output)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseLog() returned %d entries, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Hash != w.Hash || g.AuthorName != w.AuthorName || g.AuthorEmail != w.AuthorEmail ||
+					!g.Date.Equal(w.Date) || g.Subject != w.Subject || g.Body != w.Body || !slices.Equal(g.Files, w.Files) {
+					t.Errorf("entry %d = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestLogEntryShortHash(t *testing.T) {
+	if got := (LogEntry{Hash: "0123456789abcdef"}).ShortHash(); got != "0123456" {
+		t.Errorf("ShortHash() = %q", got)
+	}
+	if got := (LogEntry{Hash: "abc"}).ShortHash(); got != "abc" {
+		t.Errorf("ShortHash() = %q", got)
+	}
+}
@@ -0,0 +1,219 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrRemoteNotFound indicates that the requested git remote is not configured
+var ErrRemoteNotFound = errors.New("git remote not found")
+
+// Provider identifies the forge hosting a repository
+type Provider string
+
+const (
+	ProviderUnknown   Provider = ""
+	ProviderGitHub    Provider = "github"
+	ProviderGitLab    Provider = "gitlab"
+	ProviderBitbucket Provider = "bitbucket"
+	ProviderGitea     Provider = "gitea"
+)
+
+// DetectProvider guesses the forge from a remote host name.
+// Self-hosted instances are recognised when the host contains the forge name.
+func DetectProvider(host string) Provider {
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "github"):
+		return ProviderGitHub
+	case strings.Contains(host, "gitlab"):
+		return ProviderGitLab
+	case strings.Contains(host, "bitbucket"):
+		return ProviderBitbucket
+	case strings.Contains(host, "gitea"), strings.Contains(host, "codeberg"):
+		return ProviderGitea
+	}
+	return ProviderUnknown
+}
+
+// Project is a repository on a forge, derived from a remote URL
+type Project struct {
+	Provider Provider
+	// Scheme and Host of the web interface
+	Scheme string
+	Host   string
+	// Path is the full project path, e.g. "owner/name" or "group/sub/name"
+	Path string
+}
+
+// ProjectFromURL builds a Project from a remote URL as returned by ParseURL
+func ProjectFromURL(u *url.URL) (*Project, error) {
+	if u == nil {
+		return nil, fmt.Errorf("no remote URL")
+	}
+
+	p := strings.Trim(u.Path, "/")
+	p = strings.TrimSuffix(p, ".git")
+	if u.Host == "" || !strings.Contains(p, "/") {
+		return nil, fmt.Errorf("unable to determine project from URL: %s", u.String())
+	}
+
+	scheme := "https"
+	if u.Scheme == "http" {
+		scheme = "http"
+	}
+
+	host := webHost(u)
+
+	return &Project{
+		Provider: DetectProvider(host),
+		Scheme:   scheme,
+		Host:     host,
+		Path:     p,
+	}, nil
+}
+
+// ProjectFromShorthand expands "owner/name" or "group/sub/name" to a
+// Project on host. A leading segment containing a dot is taken as the host,
+// e.g. "gitlab.example.com/group/name", and full git URLs are parsed as is.
+func ProjectFromShorthand(s, host string) (*Project, error) {
+	if IsValidURL(s) {
+		u, err := ParseURL(s)
+		if err != nil {
+			return nil, err
+		}
+		return ProjectFromURL(u)
+	}
+
+	p := strings.TrimSuffix(strings.Trim(s, "/"), ".git")
+	if first, rest, ok := strings.Cut(p, "/"); ok && strings.Contains(first, ".") && strings.Contains(rest, "/") {
+		host, p = first, rest
+	}
+	if !strings.Contains(p, "/") {
+		return nil, fmt.Errorf("expected owner/name, got %q", s)
+	}
+	if host == "" {
+		return nil, fmt.Errorf("no host to expand %q with", s)
+	}
+
+	u, err := url.Parse("https://" + strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://") + "/" + p)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(host, "http://") {
+		u.Scheme = "http"
+	}
+	return ProjectFromURL(u)
+}
+
+// RemoteProject resolves the Project for the named git remote
+func RemoteProject(name string) (*Project, error) {
+	remotes, err := Remotes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range remotes {
+		if r.Name != name {
+			continue
+		}
+		u := r.FetchURL
+		if u == nil {
+			u = r.PushURL
+		}
+		return ProjectFromURL(u)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrRemoteNotFound, name)
+}
+
+// Owner returns everything before the last path segment
+func (p *Project) Owner() string {
+	if i := strings.LastIndex(p.Path, "/"); i >= 0 {
+		return p.Path[:i]
+	}
+	return ""
+}
+
+// Name returns the last path segment
+func (p *Project) Name() string {
+	return p.Path[strings.LastIndex(p.Path, "/")+1:]
+}
+
+// WebURL returns the browsable URL of the project
+func (p *Project) WebURL() string {
+	return fmt.Sprintf("%s://%s/%s", p.Scheme, p.Host, p.Path)
+}
+
+// IssueURL returns the URL of an issue, or "" when the provider is unknown
+func (p *Project) IssueURL(id string) string {
+	switch p.Provider {
+	case ProviderGitLab:
+		return p.WebURL() + "/-/issues/" + id
+	case ProviderGitHub, ProviderBitbucket, ProviderGitea:
+		return p.WebURL() + "/issues/" + id
+	}
+	return ""
+}
+
+// MergeRequestURL returns the URL of a merge/pull request, or "" when the
+// provider is unknown
+func (p *Project) MergeRequestURL(id string) string {
+	switch p.Provider {
+	case ProviderGitLab:
+		return p.WebURL() + "/-/merge_requests/" + id
+	case ProviderGitHub:
+		return p.WebURL() + "/pull/" + id
+	case ProviderBitbucket:
+		return p.WebURL() + "/pull-requests/" + id
+	case ProviderGitea:
+		return p.WebURL() + "/pulls/" + id
+	}
+	return ""
+}
+
+// CompareURL returns the URL comparing two refs, or "" when the provider is
+// unknown
+func (p *Project) CompareURL(from, to string) string {
+	switch p.Provider {
+	case ProviderGitLab:
+		return fmt.Sprintf("%s/-/compare/%s...%s", p.WebURL(), from, to)
+	case ProviderGitHub, ProviderGitea:
+		return fmt.Sprintf("%s/compare/%s...%s", p.WebURL(), from, to)
+	case ProviderBitbucket:
+		// bitbucket takes the newer ref first, separated by a carriage return
+		return fmt.Sprintf("%s/branches/compare/%s%%0D%s", p.WebURL(), to, from)
+	}
+	return ""
+}
+
+// TagURL returns the URL of a tag, or "" when the provider is unknown
+func (p *Project) TagURL(tag string) string {
+	switch p.Provider {
+	case ProviderGitLab:
+		return p.WebURL() + "/-/tags/" + tag
+	case ProviderGitHub:
+		return p.WebURL() + "/releases/tag/" + tag
+	case ProviderBitbucket:
+		return p.WebURL() + "/src/" + tag
+	case ProviderGitea:
+		return p.WebURL() + "/src/tag/" + tag
+	}
+	return ""
+}
+
+// HTTPSCloneURL returns the URL to clone the project over http(s)
+func (p *Project) HTTPSCloneURL() string {
+	return p.WebURL() + ".git"
+}
+
+// SSHCloneURL returns the scp-like URL to clone the project over ssh
+func (p *Project) SSHCloneURL() string {
+	return fmt.Sprintf("git@%s:%s.git", strings.Split(p.Host, ":")[0], p.Path)
+}
+
+func (p *Project) String() string {
+	return p.Host + "/" + p.Path
+}
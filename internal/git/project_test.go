@@ -0,0 +1,67 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestProjectFromURL(t *testing.T) {
+	tests := []struct {
+		remote   string
+		provider Provider
+		scheme   string
+		host     string
+		path     string
+	}{
+		{"git@github.com:owner/name.git", ProviderGitHub, "https", "github.com", "owner/name"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/name.git", ProviderGitLab, "https", "gitlab.example.com", "group/sub/name"},
+		{"https://gitlab.com/group/name", ProviderGitLab, "https", "gitlab.com", "group/name"},
+		{"https://git.example.com:8443/owner/name.git", ProviderUnknown, "https", "git.example.com:8443", "owner/name"},
+		{"https://github.example.com:443/owner/name", ProviderGitHub, "https", "github.example.com", "owner/name"},
+		{"http://gitea.local:3000/owner/name", ProviderGitea, "http", "gitea.local:3000", "owner/name"},
+		{"git://codeberg.org:9418/owner/name.git", ProviderGitea, "https", "codeberg.org", "owner/name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			u, err := ParseURL(tt.remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := ProjectFromURL(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Provider != tt.provider || p.Scheme != tt.scheme || p.Host != tt.host || p.Path != tt.path {
+				t.Errorf("ProjectFromURL() = %+v, want %s %s://%s/%s", p, tt.provider, tt.scheme, tt.host, tt.path)
+			}
+		})
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"GitLab.example.com", "gitlab.example.com"},
+		{"gitlab.example.com/group/name", "gitlab.example.com"},
+		{"git.example.com:8443", "git.example.com:8443"},
+		{"https://git.example.com:8443/owner/name.git", "git.example.com:8443"},
+		{"https://git.example.com:443", "git.example.com"},
+		{"git@gitlab.example.com:group/name.git", "gitlab.example.com"},
+		{"ssh://git@gitlab.example.com:2222/group/name.git", "gitlab.example.com"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeHost(tt.in)
+		if err != nil {
+			t.Errorf("NormalizeHost(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeHost(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := NormalizeHost(" "); err == nil {
+		t.Error("expected an error for an empty host")
+	}
+}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+// ErrPushRejected indicates that the remote refused to update some refs
+var ErrPushRejected = errors.New("push rejected")
+
+// PushOptions configures PushRefs
+type PushOptions struct {
+	Remote string
+	// Refs are refspecs, e.g. "main" or "refs/tags/v1.0.0"
+	Refs []string
+	// ForceWithLease overwrites remote refs only if they still point at the
+	// commit of the remote-tracking branch. Leases maps a remote ref to the
+	// commit it is expected at instead.
+	ForceWithLease bool
+	Leases         map[string]string
+	// FollowTags also pushes annotated tags pointing at pushed commits
+	FollowTags bool
+	// Atomic updates either every ref or none of them
+	Atomic bool
+	// SetUpstream makes the remote branch the upstream of the local branch
+	SetUpstream bool
+	// PushOptions are passed to the server hooks with -o, e.g.
+	// "merge_request.create" on GitLab
+	PushOptions []string
+	DryRun      bool
+}
+
+// PushFlag is the kind of update reported for a ref by `git push --porcelain`
+type PushFlag byte
+
+const (
+	PushFastForward PushFlag = ' '
+	PushForced      PushFlag = '+'
+	PushDeleted     PushFlag = '-'
+	PushNew         PushFlag = '*'
+	PushRejected    PushFlag = '!'
+	PushUpToDate    PushFlag = '='
+)
+
+// PushResult is the outcome of pushing a single ref
+type PushResult struct {
+	Flag PushFlag
+	// Local and Remote are the full ref names of the source and destination
+	Local  string
+	Remote string
+	// Summary is e.g. "abc123..def456", "[new tag]" or "[rejected]"
+	Summary string
+	// Reason explains rejections, e.g. "non-fast-forward" or "stale info"
+	Reason string
+}
+
+// OK reports whether the remote ref now matches the local one
+func (r PushResult) OK() bool {
+	return r.Flag != PushRejected
+}
+
+// Name returns the remote branch or tag name without its refs/ prefix
+func (r PushResult) Name() string {
+	return strings.TrimPrefix(strings.TrimPrefix(r.Remote, "refs/heads/"), "refs/tags/")
+}
+
+func (r PushResult) String() string {
+	if r.Reason != "" {
+		return fmt.Sprintf("%s: %s (%s)", r.Name(), r.Summary, r.Reason)
+	}
+	return fmt.Sprintf("%s: %s", r.Name(), r.Summary)
+}
+
+// PushRefs pushes refs to a remote and returns the result of every ref. When
+// the remote rejects a ref the results are returned along with an error
+// wrapping ErrPushRejected.
+func PushRefs(opts PushOptions) ([]PushResult, error) {
+	if opts.Remote == "" {
+		return nil, errors.New("no remote to push to")
+	}
+
+	args := []string{"push", "--porcelain"}
+	if opts.ForceWithLease && len(opts.Leases) == 0 {
+		args = append(args, "--force-with-lease")
+	}
+	for _, ref := range slices.Sorted(maps.Keys(opts.Leases)) {
+		args = append(args, fmt.Sprintf("--force-with-lease=%s:%s", ref, opts.Leases[ref]))
+	}
+	if opts.FollowTags {
+		args = append(args, "--follow-tags")
+	}
+	if opts.Atomic {
+		args = append(args, "--atomic")
+	}
+	if opts.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	for _, o := range opts.PushOptions {
+		args = append(args, "--push-option="+o)
+	}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	args = append(args, opts.Remote)
+	args = append(args, opts.Refs...)
+
+	pushCmd := GitCommand(args...)
+	output, err := run.PrepareCmd(pushCmd).Output()
+	results := ParsePushPorcelain(string(output))
+
+	var rejected []string
+	for _, r := range results {
+		if !r.OK() {
+			rejected = append(rejected, r.String())
+		}
+	}
+	if len(rejected) > 0 {
+		return results, fmt.Errorf("%w by %s: %s", ErrPushRejected, opts.Remote, strings.Join(rejected, ", "))
+	}
+	if err != nil {
+		return results, fmt.Errorf("%w: %w", ErrPushFailed, err)
+	}
+	return results, nil
+}
+
+// ParsePushPorcelain reads the ref lines of `git push --porcelain` output
+func ParsePushPorcelain(output string) []PushResult {
+	var results []PushResult
+	for _, line := range strings.Split(output, "\n") {
+		// ref lines are "<flag>\t<from>:<to>\t<summary> (<reason>)"
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || len(fields[0]) != 1 {
+			continue
+		}
+
+		local, remote, _ := strings.Cut(fields[1], ":")
+		result := PushResult{
+			Flag:    PushFlag(fields[0][0]),
+			Local:   local,
+			Remote:  remote,
+			Summary: fields[2],
+		}
+		if summary, reason, ok := strings.Cut(fields[2], " ("); ok {
+			result.Summary = summary
+			result.Reason = strings.TrimSuffix(reason, ")")
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,147 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+// RemoteResolutionKey is the remote config key marking the base remote,
+// e.g. `remote.upstream.cliborg-resolved = base`
+const RemoteResolutionKey = "cliborg-resolved"
+
+// legacyRemoteResolutionKey is the key glab and earlier versions of cliborg
+// used, still read when the remote has no RemoteResolutionKey
+const legacyRemoteResolutionKey = "glab-resolved"
+
+// ResolutionBase marks the remote of the base repository
+const ResolutionBase = "base"
+
+// ErrNoRemotes indicates that the repository has no remotes
+var ErrNoRemotes = errors.New("no git remotes found")
+
+// remotePriority orders remotes when none was picked with SetBaseRemote
+var remotePriority = []string{"upstream", "github", DefaultRemote}
+
+// BaseRemote returns the remote of the base repository: the one picked with
+// SetBaseRemote, otherwise upstream, github or origin in that order, and
+// otherwise the first remote
+func BaseRemote() (*Remote, error) {
+	remotes, err := Remotes()
+	if err != nil {
+		return nil, err
+	}
+	if len(remotes) == 0 {
+		return nil, ErrNoRemotes
+	}
+
+	for _, r := range remotes {
+		if r.Resolved == ResolutionBase {
+			return r, nil
+		}
+	}
+	for _, name := range remotePriority {
+		for _, r := range remotes {
+			if r.Name == name {
+				return r, nil
+			}
+		}
+	}
+	return remotes[0], nil
+}
+
+// BaseRemoteName returns the name of BaseRemote, falling back to origin
+func BaseRemoteName() string {
+	r, err := BaseRemote()
+	if err != nil {
+		return DefaultRemote
+	}
+	return r.Name
+}
+
+// SetBaseRemote marks the named remote as the base repository, clearing the
+// mark from every other remote
+func SetBaseRemote(name string) error {
+	remotes, err := Remotes()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, r := range remotes {
+		if r.Name == name {
+			found = true
+			continue
+		}
+		if r.Resolved != "" {
+			if err := unsetRemoteResolution(r.Name); err != nil {
+				return err
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrRemoteNotFound, name)
+	}
+
+	return SetRemoteResolution(name, ResolutionBase)
+}
+
+// SetBaseRemoteIn marks the named remote as the base repository of the
+// repository in dir, e.g. right after cloning it
+func SetBaseRemoteIn(dir, name string) error {
+	configCmd := GitCommand("-C", dir, "config", fmt.Sprintf("remote.%s.%s", name, RemoteResolutionKey), ResolutionBase)
+	_, err := run.PrepareCmd(configCmd).Output()
+	if err != nil {
+		return fmt.Errorf("setting git config: %w", err)
+	}
+	return nil
+}
+
+// UnsetBaseRemote clears the base repository mark from every remote
+func UnsetBaseRemote() error {
+	remotes, err := Remotes()
+	if err != nil {
+		return err
+	}
+	for _, r := range remotes {
+		if r.Resolved == "" {
+			continue
+		}
+		if err := unsetRemoteResolution(r.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unsetRemoteResolution clears the mark of a remote, including one left by glab
+func unsetRemoteResolution(name string) error {
+	for _, key := range []string{RemoteResolutionKey, legacyRemoteResolutionKey} {
+		if err := UnsetConfig(fmt.Sprintf("remote.%s.%s", name, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnsetConfig removes every value of a local config key
+func UnsetConfig(key string) error {
+	err := assertValidConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	unsetCmd := GitCommand("config", "--unset-all", key)
+	_, err = run.PrepareCmd(unsetCmd).Output()
+	if err == nil {
+		return nil
+	}
+
+	// git-config exits with 5 without output when the key is not set
+	var cmdErr *run.CmdError
+	if errors.As(err, &cmdErr) && cmdErr.Stderr.Len() == 0 {
+		return nil
+	}
+	return fmt.Errorf("unsetting git config: %w", err)
+}
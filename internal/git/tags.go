@@ -0,0 +1,267 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+	"github.com/nick-ccc/CLIborg/internal/version"
+)
+
+// ErrTagNotFound indicates that a local tag does not exist
+var ErrTagNotFound = errors.New("tag not found")
+
+// TagType tells lightweight tags, plain refs to a commit, from annotated
+// tags, which are objects with a tagger, date and message
+type TagType string
+
+const (
+	TagLightweight TagType = "lightweight"
+	TagAnnotated   TagType = "annotated"
+)
+
+// tagFormat separates the fields with NUL and ends each tag with a record
+// separator, as annotation messages span several lines
+const tagFormat = "%(refname:strip=2)%00%(objecttype)%00%(objectname)%00%(*objectname)%00" +
+	"%(taggername)%00%(taggeremail:trim)%00%(creatordate:iso-strict)%00%(contents)%1e"
+
+// tagVersionRE finds the semantic version at the end of a tag name, after an
+// optional package prefix
+var tagVersionRE = regexp.MustCompile(`v?\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Tag is a tag with the metadata of its annotation
+type Tag struct {
+	Name string
+	// SHA is the commit the tag points at, peeled for annotated tags
+	SHA  string
+	Type TagType
+	// Tagger, TaggerEmail and Message are only set for annotated tags
+	Tagger      string
+	TaggerEmail string
+	// Date is the tagger date of annotated tags and the commit date of
+	// lightweight tags
+	Date    time.Time
+	Message string
+}
+
+// Version returns the semantic version at the end of the tag name and the
+// prefix before it, e.g. "svc-a/" for "svc-a/v1.2.0"
+func (t Tag) Version() (version.Version, string, bool) {
+	loc := tagVersionRE.FindStringIndex(t.Name)
+	if loc == nil {
+		return version.Version{}, "", false
+	}
+	v, err := version.Parse(t.Name[loc[0]:])
+	if err != nil {
+		return version.Version{}, "", false
+	}
+	return v, t.Name[:loc[0]], true
+}
+
+// TagFilter selects tags by name. Patterns are globs as in path.Match, a tag
+// matching any of them is kept; no patterns keep every tag.
+type TagFilter struct {
+	Patterns []string
+	Prefix   string
+}
+
+// Match reports whether a tag name passes the filter
+func (f TagFilter) Match(name string) bool {
+	if !strings.HasPrefix(name, f.Prefix) {
+		return false
+	}
+	if len(f.Patterns) == 0 {
+		return true
+	}
+	for _, p := range f.Patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags lists the tags passing the filter in ascending version order, see
+// SortTags
+func Tags(filter TagFilter) ([]Tag, error) {
+	for _, p := range filter.Patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid tag pattern %q: %w", p, err)
+		}
+	}
+
+	listCmd := GitCommand("for-each-ref", "--format="+tagFormat, "refs/tags")
+	output, err := run.PrepareCmd(listCmd).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+
+	tags, err := parseTags(string(output))
+	if err != nil {
+		return nil, err
+	}
+	tags = slices.DeleteFunc(tags, func(t Tag) bool {
+		return !filter.Match(t.Name)
+	})
+	SortTags(tags)
+	return tags, nil
+}
+
+func parseTags(output string) ([]Tag, error) {
+	var tags []Tag
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x00", 8)
+		if len(fields) != 8 {
+			return nil, fmt.Errorf("unexpected tag listing: %q", record)
+		}
+
+		t := Tag{Name: fields[0], SHA: fields[2], Type: TagLightweight}
+		if fields[1] == "tag" {
+			t.Type = TagAnnotated
+			t.SHA = fields[3]
+			t.Tagger = fields[4]
+			t.TaggerEmail = fields[5]
+			t.Message = strings.TrimSpace(fields[7])
+		}
+		if fields[6] != "" {
+			date, err := time.Parse(time.RFC3339, fields[6])
+			if err != nil {
+				return nil, fmt.Errorf("could not parse the date of tag %s: %w", t.Name, err)
+			}
+			t.Date = date
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// SortTags orders tags by prefix and then by semantic version, so
+// pre-releases come before their final release. Tags without a version sort
+// by name before the versioned ones.
+func SortTags(tags []Tag) {
+	slices.SortStableFunc(tags, func(a, b Tag) int {
+		return compareTagNames(a.Name, b.Name)
+	})
+}
+
+// SortTagNames orders tag names like SortTags
+func SortTagNames(names []string) {
+	slices.SortStableFunc(names, compareTagNames)
+}
+
+func compareTagNames(a, b string) int {
+	av, ap, aok := Tag{Name: a}.Version()
+	bv, bp, bok := Tag{Name: b}.Version()
+	switch {
+	case aok && bok:
+		if c := strings.Compare(ap, bp); c != 0 {
+			return c
+		}
+		if c := version.Compare(av, bv); c != 0 {
+			return c
+		}
+	case aok:
+		return 1
+	case bok:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// FindTag returns the local tag with the given name
+func FindTag(name string) (*Tag, error) {
+	tags, err := Tags(TagFilter{Prefix: name})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		if t.Name == name {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTagNotFound, name)
+}
+
+// TagDate returns when a tag was made: the tagger date of an annotated tag,
+// otherwise the date of the tagged commit
+func TagDate(name string) (time.Time, error) {
+	t, err := FindTag(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Date, nil
+}
+
+// RemoteTag is a tag published on a remote
+type RemoteTag struct {
+	Name string
+	// SHA is the commit the tag points at
+	SHA string
+}
+
+// RemoteTags lists the tags of a remote in ascending version order
+func RemoteTags(remote string) ([]RemoteTag, error) {
+	lsCmd := GitCommand("ls-remote", "--tags", remote)
+	output, err := run.PrepareCmd(lsCmd).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing tags on %s: %w", remote, err)
+	}
+	return parseRemoteTags(output), nil
+}
+
+// parseRemoteTags reads `git ls-remote --tags` output. Annotated tags are
+// listed twice, the "^{}" line holds the commit they point at.
+func parseRemoteTags(output []byte) []RemoteTag {
+	var names []string
+	listed := map[string]bool{}
+	shas := map[string]string{}
+	for _, line := range outputLines(output) {
+		sha, ref, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		name := strings.TrimPrefix(ref, "refs/tags/")
+		peeled, isPeeled := strings.CutSuffix(name, "^{}")
+		if !listed[peeled] {
+			listed[peeled] = true
+			names = append(names, peeled)
+		}
+		if _, seen := shas[peeled]; isPeeled || !seen {
+			shas[peeled] = sha
+		}
+	}
+
+	SortTagNames(names)
+	tags := make([]RemoteTag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, RemoteTag{Name: name, SHA: shas[name]})
+	}
+	return tags
+}
+
+// RemoteTagExists reports whether a tag is published on the remote
+func RemoteTagExists(remote, name string) (bool, error) {
+	lsCmd := GitCommand("ls-remote", "--exit-code", "--tags", remote, "refs/tags/"+name)
+	_, err := run.PrepareCmd(lsCmd).Output()
+	if err == nil {
+		return true, nil
+	}
+
+	// --exit-code exits with 2 when nothing matched, the remote may still
+	// print warnings to stderr
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+		return false, nil
+	}
+	return false, fmt.Errorf("checking for tag %s on %s: %w", name, remote, err)
+}
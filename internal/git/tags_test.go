@@ -0,0 +1,143 @@
+package git
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTags(t *testing.T) {
+	record := func(fields ...string) string {
+		return strings.Join(fields, "\x00") + "\x1e"
+	}
+	output := record("v1.0.0", "commit", "aaa", "", "", "", "2025-01-01T10:00:00Z", "feat: a\n") + "\n" +
+		record("v1.1.0", "tag", "ttt", "bbb", "Ann", "ann@example.com", "2025-02-01T10:00:00+01:00", "Release v1.1.0\n\nNotes: x\n") + "\n" +
+		record("nodate", "commit", "ccc", "", "", "", "", "") + "\n"
+
+	tags, err := parseTags(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Tag{
+		{Name: "v1.0.0", SHA: "aaa", Type: TagLightweight, Date: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{Name: "v1.1.0", SHA: "bbb", Type: TagAnnotated, Tagger: "Ann", TaggerEmail: "ann@example.com", Date: time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC), Message: "Release v1.1.0\n\nNotes: x"},
+		{Name: "nodate", SHA: "ccc", Type: TagLightweight},
+	}
+	if len(tags) != len(want) {
+		t.Fatalf("parseTags() = %+v, want %+v", tags, want)
+	}
+	for i, tag := range tags {
+		w := want[i]
+		if tag.Name != w.Name || tag.SHA != w.SHA || tag.Type != w.Type || tag.Tagger != w.Tagger ||
+			tag.TaggerEmail != w.TaggerEmail || !tag.Date.Equal(w.Date) || tag.Message != w.Message {
+			t.Errorf("tag %d = %+v, want %+v", i, tag, w)
+		}
+	}
+
+	if _, err := parseTags(record("v1.0.0", "commit")); err == nil {
+		t.Error("expected an error for a truncated record")
+	}
+	if _, err := parseTags(record("v1.0.0", "commit", "aaa", "", "", "", "yesterday", "")); err == nil {
+		t.Error("expected an error for an invalid date")
+	}
+}
+
+func TestParseRemoteTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []RemoteTag
+	}{
+		{
+			name:   "empty",
+			output: "",
+			want:   []RemoteTag{},
+		},
+		{
+			name: "lightweight and annotated",
+			output: "aaa\trefs/tags/v1.10.0\n" +
+				"ttt\trefs/tags/v1.2.0\n" +
+				"bbb\trefs/tags/v1.2.0^{}\n" +
+				"ccc\trefs/tags/svc/v0.1.0\n",
+			want: []RemoteTag{
+				{Name: "v1.2.0", SHA: "bbb"},
+				{Name: "v1.10.0", SHA: "aaa"},
+				{Name: "svc/v0.1.0", SHA: "ccc"},
+			},
+		},
+		{
+			name:   "peeled line first",
+			output: "bbb\trefs/tags/v1.2.0^{}\nttt\trefs/tags/v1.2.0\n",
+			want:   []RemoteTag{{Name: "v1.2.0", SHA: "bbb"}},
+		},
+		{
+			name:   "noise",
+			output: "warning: redirecting\nbbb\trefs/tags/v1.0.0\n",
+			want:   []RemoteTag{{Name: "v1.0.0", SHA: "bbb"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRemoteTags([]byte(tt.output))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseRemoteTags() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortTagNames(t *testing.T) {
+	names := []string{"v1.10.0", "v1.2.0", "v1.2.0-rc.10", "v1.2.0-rc.2", "latest", "svc/v2.0.0", "svc/v0.9.0", "1.0.0", "archive"}
+	SortTagNames(names)
+	want := []string{"archive", "latest", "1.0.0", "v1.2.0-rc.2", "v1.2.0-rc.10", "v1.2.0", "v1.10.0", "svc/v0.9.0", "svc/v2.0.0"}
+	if !slices.Equal(names, want) {
+		t.Errorf("SortTagNames() = %v, want %v", names, want)
+	}
+}
+
+func TestTagVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantOK     bool
+		wantPrefix string
+		wantString string
+	}{
+		{"v1.2.3", true, "", "v1.2.3"},
+		{"svc-a/v0.3.0-rc.1", true, "svc-a/", "v0.3.0-rc.1"},
+		{"release-1.0.0", true, "release-", "1.0.0"},
+		{"latest", false, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, prefix, ok := Tag{Name: tt.name}.Version()
+			if ok != tt.wantOK || prefix != tt.wantPrefix {
+				t.Fatalf("Version() = %v, %q, %v, want prefix %q and %v", v, prefix, ok, tt.wantPrefix, tt.wantOK)
+			}
+			if ok && tt.name[len(prefix):] != tt.wantString {
+				t.Errorf("version part = %q, want %q", tt.name[len(prefix):], tt.wantString)
+			}
+		})
+	}
+}
+
+func TestTagFilterMatch(t *testing.T) {
+	tests := []struct {
+		filter TagFilter
+		name   string
+		want   bool
+	}{
+		{TagFilter{}, "v1.0.0", true},
+		{TagFilter{Prefix: "svc/"}, "svc/v1.0.0", true},
+		{TagFilter{Prefix: "svc/"}, "v1.0.0", false},
+		{TagFilter{Patterns: []string{"v1.*"}}, "v1.2.0", true},
+		{TagFilter{Patterns: []string{"v1.*", "v2.*"}}, "v2.0.0", true},
+		{TagFilter{Patterns: []string{"v1.*"}}, "v2.0.0", false},
+		{TagFilter{Prefix: "svc/", Patterns: []string{"svc/v1.*"}}, "svc/v2.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(tt.name); got != tt.want {
+			t.Errorf("%+v.Match(%q) = %v, want %v", tt.filter, tt.name, got, tt.want)
+		}
+	}
+}
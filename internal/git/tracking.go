@@ -0,0 +1,194 @@
+package git
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/run"
+)
+
+// TrackingStatus is the state of a local branch relative to its upstream
+type TrackingStatus struct {
+	Branch
+	// Ahead and Behind count the commits only on the branch and only on its
+	// upstream
+	Ahead  int
+	Behind int
+	// RemoteExists reports whether the remote still has the upstream branch,
+	// only asked when checking the remote, otherwise !Gone
+	RemoteExists bool
+	// RemoteErr is why the remote could not be asked, RemoteExists then
+	// relies on the last fetch
+	RemoteErr  error
+	LastCommit *LogEntry
+}
+
+// HasUpstream reports whether the branch tracks a remote branch
+func (s *TrackingStatus) HasUpstream() bool {
+	return s.Upstream != ""
+}
+
+// InSync reports whether the branch and its upstream point at the same commit.
+// It is false when the remote could not be asked.
+func (s *TrackingStatus) InSync() bool {
+	return s.HasUpstream() && s.RemoteExists && s.RemoteErr == nil && s.Ahead == 0 && s.Behind == 0
+}
+
+// Summary describes the tracking state in a few words
+func (s *TrackingStatus) Summary() string {
+	if s.RemoteErr != nil {
+		return s.summary() + ", remote unreachable"
+	}
+	return s.summary()
+}
+
+func (s *TrackingStatus) summary() string {
+	switch {
+	case !s.HasUpstream():
+		return "no upstream"
+	case !s.RemoteExists:
+		return "upstream gone"
+	case s.Ahead > 0 && s.Behind > 0:
+		return fmt.Sprintf("ahead %d, behind %d", s.Ahead, s.Behind)
+	case s.Ahead > 0:
+		return fmt.Sprintf("ahead %d", s.Ahead)
+	case s.Behind > 0:
+		return fmt.Sprintf("behind %d", s.Behind)
+	}
+	return "up to date"
+}
+
+// BranchStatuses returns the tracking state of every local branch. With
+// checkRemote the remote is asked whether upstream branches still exist,
+// otherwise the remote-tracking refs of the last fetch are trusted.
+func BranchStatuses(checkRemote bool) ([]TrackingStatus, error) {
+	branches, err := LocalBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	heads := remoteHeadsCache{}
+	statuses := make([]TrackingStatus, 0, len(branches))
+	for _, b := range branches {
+		status, err := trackingStatus(b, checkRemote, heads)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// BranchStatus returns the tracking state of a single local branch
+func BranchStatus(name string, checkRemote bool) (*TrackingStatus, error) {
+	branches, err := LocalBranches()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		if b.Name == name {
+			return trackingStatus(b, checkRemote, nil)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrBranchNotFound, name)
+}
+
+// remoteHeadsCache holds the branches of each remote asked so far, so every
+// remote is only listed once. A nil cache asks the remote for each branch.
+type remoteHeadsCache map[string]remoteHeads
+
+type remoteHeads struct {
+	branches []string
+	err      error
+}
+
+// exists reports whether the remote has the branch, listing its branches on
+// first use
+func (c remoteHeadsCache) exists(remote, branch string) (bool, error) {
+	if c == nil {
+		return RemoteBranchExists(remote, branch)
+	}
+	heads, ok := c[remote]
+	if !ok {
+		heads.branches, heads.err = RemoteBranches(remote)
+		c[remote] = heads
+	}
+	if heads.err != nil {
+		return false, heads.err
+	}
+	return slices.Contains(heads.branches, branch), nil
+}
+
+func trackingStatus(b Branch, checkRemote bool, heads remoteHeadsCache) (*TrackingStatus, error) {
+	status := &TrackingStatus{Branch: b, RemoteExists: b.Upstream != "" && !b.Gone}
+
+	last, err := LastCommit("refs/heads/" + b.Name)
+	if err != nil {
+		return nil, err
+	}
+	status.LastCommit = last
+
+	if !status.RemoteExists {
+		return status, nil
+	}
+
+	if checkRemote {
+		remote, branch, err := upstreamRemote(b.Name)
+		// branches tracking another local branch have no remote to ask
+		if err == nil && remote != "." {
+			var exists bool
+			exists, err = heads.exists(remote, branch)
+			if err == nil {
+				status.RemoteExists = exists
+			}
+		}
+		// an unreachable remote leaves the status of the last fetch
+		status.RemoteErr = err
+	}
+
+	status.Ahead, status.Behind, err = AheadBehind(b.Name, b.Upstream)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// AheadBehind counts the commits only reachable from ref and only reachable
+// from upstream
+func AheadBehind(ref, upstream string) (int, int, error) {
+	countCmd := GitCommand("rev-list", "--left-right", "--count", ref+"..."+upstream, "--")
+	output, err := run.PrepareCmd(countCmd).Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("comparing %s with %s: %w", ref, upstream, err)
+	}
+
+	fields := strings.Fields(firstLine(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+// upstreamRemote returns the remote and the remote branch name a local
+// branch tracks, as configured by SetUpstream
+func upstreamRemote(branch string) (string, string, error) {
+	remote, err := GetAllConfig("branch." + branch + ".remote")
+	if err != nil {
+		return "", "", err
+	}
+	merge, err := GetAllConfig("branch." + branch + ".merge")
+	if err != nil {
+		return "", "", err
+	}
+	return firstLine(remote), strings.TrimPrefix(firstLine(merge), "refs/heads/"), nil
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"net/url"
 	"strings"
 )
@@ -58,3 +59,39 @@ func ParseURL(rawURL string) (*url.URL, error) {
 func IsValidURL(u string) bool {
 	return strings.HasPrefix(u, "git@") || isSupportedProtocol(u)
 }
+
+// NormalizeHost returns the lower case host name of a remote URL, a
+// "host/path" string or a bare host name
+func NormalizeHost(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("empty host")
+	}
+
+	if !strings.Contains(s, "://") && !strings.HasPrefix(s, "git@") {
+		// bare host, optionally followed by a project path
+		s = "https://" + s
+	}
+
+	u, err := ParseURL(s)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("no host in %q", s)
+	}
+	return strings.ToLower(webHost(u)), nil
+}
+
+// webHost returns the host of a remote URL as served over http. The port of
+// http and https URLs is kept unless it is the default one, other ports are
+// those of ssh or git and dropped.
+func webHost(u *url.URL) string {
+	switch {
+	case u.Scheme == "https" && u.Port() == "443", u.Scheme == "http" && u.Port() == "80":
+		return u.Hostname()
+	case u.Scheme == "https", u.Scheme == "http":
+		return u.Host
+	}
+	return u.Hostname()
+}
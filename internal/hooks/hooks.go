@@ -0,0 +1,224 @@
+// Package hooks installs the git hooks that run cliborg checks locally.
+package hooks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// Hook names managed by cliborg
+const (
+	CommitMsg        = "commit-msg"
+	PrePush          = "pre-push"
+	PrepareCommitMsg = "prepare-commit-msg"
+)
+
+// Names lists every hook cliborg can install
+var Names = []string{CommitMsg, PrePush, PrepareCommitMsg}
+
+// ChainedSuffix is appended to hooks that existed before cliborg's, which
+// keep running before the cliborg check
+const ChainedSuffix = ".pre-cliborg"
+
+// marker identifies hook scripts written by cliborg
+const marker = "# Installed by cliborg"
+
+// Status is the state of a hook
+type Status string
+
+const (
+	StatusMissing   Status = "not installed"
+	StatusInstalled Status = "installed"
+	StatusChained   Status = "installed, runs the previous hook first"
+	StatusForeign   Status = "another hook is installed"
+)
+
+// Hook is a hook in the hooks directory
+type Hook struct {
+	Name   string
+	Path   string
+	Status Status
+}
+
+var scriptTemplate = template.Must(template.New("hook").Funcs(template.FuncMap{"shellQuote": shellQuote}).Parse(`#!/bin/sh
+` + marker + `, remove with "cliborg hooks uninstall".
+hook_dir=$(dirname "$0")
+chained="$hook_dir/{{.Name}}` + ChainedSuffix + `"
+{{if .Stdin}}
+# the refs being pushed arrive on stdin, which both hooks need
+input=$(cat)
+if [ -x "$chained" ]; then
+	printf '%s\n' "$input" | "$chained" "$@" || exit $?
+fi
+{{else}}
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+{{end}}
+if command -v cliborg >/dev/null 2>&1; then
+	cliborg=cliborg
+elif [ -x {{shellQuote .Executable}} ]; then
+	cliborg={{shellQuote .Executable}}
+else
+	echo "cliborg not found, skipping the {{.Name}} hook" >&2
+	exit 0
+fi
+{{if .Stdin}}
+printf '%s\n' "$input" | "$cliborg" hooks run {{.Name}} "$@"
+{{else}}
+exec "$cliborg" hooks run {{.Name}} "$@"
+{{end}}`))
+
+// Script returns the hook script for name. executable is used when cliborg
+// is not on the PATH of the process running the hook.
+func Script(name, executable string) (string, error) {
+	if !slices.Contains(Names, name) {
+		return "", fmt.Errorf("unknown hook %q, expected one of %s", name, strings.Join(Names, ", "))
+	}
+
+	var buf bytes.Buffer
+	err := scriptTemplate.Execute(&buf, struct {
+		Name       string
+		Executable string
+		Stdin      bool
+	}{name, executable, name == PrePush})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// List returns the state of every hook cliborg can install
+func List() ([]Hook, error) {
+	dir, err := git.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := make([]Hook, 0, len(Names))
+	for _, name := range Names {
+		status, err := hookStatus(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, Hook{Name: name, Path: filepath.Join(dir, name), Status: status})
+	}
+	return hooks, nil
+}
+
+// Install writes the hooks, moving existing hooks aside so they keep running
+// before the cliborg check. Hooks installed by cliborg are updated.
+func Install(names []string, executable string) ([]Hook, error) {
+	dir, err := git.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	var installed []Hook
+	for _, name := range names {
+		script, err := Script(name, executable)
+		if err != nil {
+			return installed, err
+		}
+
+		path := filepath.Join(dir, name)
+		status, err := hookStatus(path)
+		if err != nil {
+			return installed, err
+		}
+		if status == StatusForeign {
+			chained := path + ChainedSuffix
+			if _, err := os.Stat(chained); err == nil {
+				return installed, fmt.Errorf("cannot keep %s, %s already exists", path, chained)
+			}
+			if err := os.Rename(path, chained); err != nil {
+				return installed, fmt.Errorf("failed to move %s aside: %w", path, err)
+			}
+		}
+
+		if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+			return installed, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		// WriteFile keeps the mode of files that existed
+		if err := os.Chmod(path, 0755); err != nil {
+			return installed, err
+		}
+
+		status, err = hookStatus(path)
+		if err != nil {
+			return installed, err
+		}
+		installed = append(installed, Hook{Name: name, Path: path, Status: status})
+	}
+	return installed, nil
+}
+
+// Uninstall removes the hooks written by cliborg and restores the hooks they
+// chained. Hooks not written by cliborg are left alone.
+func Uninstall(names []string) ([]Hook, error) {
+	dir, err := git.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Hook
+	for _, name := range names {
+		if !slices.Contains(Names, name) {
+			return removed, fmt.Errorf("unknown hook %q, expected one of %s", name, strings.Join(Names, ", "))
+		}
+
+		path := filepath.Join(dir, name)
+		status, err := hookStatus(path)
+		if err != nil {
+			return removed, err
+		}
+		if status != StatusInstalled && status != StatusChained {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		if status == StatusChained {
+			if err := os.Rename(path+ChainedSuffix, path); err != nil {
+				return removed, fmt.Errorf("failed to restore %s: %w", path, err)
+			}
+		}
+		removed = append(removed, Hook{Name: name, Path: path, Status: status})
+	}
+	return removed, nil
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func hookStatus(path string) (Status, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return StatusMissing, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if !bytes.Contains(data, []byte(marker)) {
+		return StatusForeign, nil
+	}
+
+	if _, err := os.Stat(path + ChainedSuffix); err == nil {
+		return StatusChained, nil
+	}
+	return StatusInstalled, nil
+}
@@ -0,0 +1,130 @@
+// Package logging configures the structured diagnostics of cliborg, written
+// with log/slog to stderr and optionally to a JSON lines trace file.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+// LevelTrace is below debug and adds the output of every command
+const LevelTrace = slog.LevelDebug - 4
+
+// TraceFileEnv names a trace file when --trace-file is not given
+const TraceFileEnv = "CLIBORG_TRACE_FILE"
+
+// Options configures Setup
+type Options struct {
+	// Verbose logs every git invocation with its timing and exit code
+	Verbose bool
+	// Trace additionally logs the stderr of successful commands
+	Trace bool
+	// TraceFile receives every record at trace level as JSON lines,
+	// whatever the stderr level
+	TraceFile string
+	// ErrOut is where records are printed, os.Stderr when nil
+	ErrOut io.Writer
+}
+
+// Level returns the stderr level selected by the options. DEBUG=true, which
+// used to print the arguments of every command, turns on verbose logging.
+func (o Options) Level() slog.Level {
+	switch {
+	case o.Trace:
+		return LevelTrace
+	case o.Verbose:
+		return slog.LevelDebug
+	}
+	if debug, err := strconv.ParseBool(os.Getenv("DEBUG")); err == nil && debug {
+		return slog.LevelDebug
+	}
+	return slog.LevelWarn
+}
+
+// Setup installs the default slog logger and returns a func closing the
+// trace file
+func Setup(opts Options) (func() error, error) {
+	errOut := opts.ErrOut
+	if errOut == nil {
+		errOut = os.Stderr
+	}
+	handlers := []slog.Handler{
+		slog.NewTextHandler(errOut, &slog.HandlerOptions{
+			Level:       opts.Level(),
+			ReplaceAttr: replaceLevel,
+		}),
+	}
+
+	closeFn := func() error { return nil }
+	path := opts.TraceFile
+	if path == "" {
+		path = os.Getenv(TraceFileEnv)
+	}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening trace file: %w", err)
+		}
+		handlers = append(handlers, slog.NewJSONHandler(f, &slog.HandlerOptions{
+			Level:       LevelTrace,
+			ReplaceAttr: replaceLevel,
+		}))
+		closeFn = f.Close
+	}
+
+	slog.SetDefault(slog.New(fanout(handlers)))
+	return closeFn, nil
+}
+
+// replaceLevel names LevelTrace, which slog would print as "DEBUG-4"
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok && level <= LevelTrace {
+			a.Value = slog.StringValue("TRACE")
+		}
+	}
+	return a
+}
+
+// fanout passes each record to every handler enabled for its level
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
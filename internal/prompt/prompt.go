@@ -0,0 +1,126 @@
+// Package prompt asks line based questions on a terminal.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrNoAnswer indicates that the input ended before a valid answer was given
+var ErrNoAnswer = errors.New("no answer given")
+
+// Prompter asks questions on out and reads the answers from in. It buffers
+// the input, so a single Prompter is used for a series of questions.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// New returns a Prompter reading answers from in
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// Select lists the options numbered from 1 and returns the index of the
+// chosen one. An empty answer picks the default, a negative default requires
+// an answer.
+func (p *Prompter) Select(message string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return 0, errors.New("nothing to select from")
+	}
+
+	for {
+		fmt.Fprintln(p.out, message)
+		for i, opt := range options {
+			marker := " "
+			if i == def {
+				marker = ">"
+			}
+			fmt.Fprintf(p.out, "%s %d. %s\n", marker, i+1, opt)
+		}
+		if def >= 0 && def < len(options) {
+			fmt.Fprintf(p.out, "Choice [%d]: ", def+1)
+		} else {
+			fmt.Fprint(p.out, "Choice: ")
+		}
+
+		answer, err := p.readLine()
+		if err != nil {
+			return 0, err
+		}
+		if answer == "" && def >= 0 && def < len(options) {
+			return def, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		// options may also be picked by name
+		for i, opt := range options {
+			fields := strings.Fields(opt)
+			if strings.EqualFold(answer, opt) || (len(fields) > 0 && strings.EqualFold(answer, fields[0])) {
+				return i, nil
+			}
+		}
+		fmt.Fprintf(p.out, "%q is not one of the options\n", answer)
+	}
+}
+
+// Input asks for a line of text, an empty answer returns the default
+func (p *Prompter) Input(message, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", message, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", message)
+	}
+
+	answer, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// Confirm asks a yes or no question, an empty answer returns the default
+func (p *Prompter) Confirm(message string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", message, hint)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y or n")
+	}
+}
+
+// readLine returns the next line without surrounding whitespace, a last line
+// without a newline is still an answer
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		if errors.Is(err, io.EOF) {
+			return "", ErrNoAnswer
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
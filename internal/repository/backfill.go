@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"errors"
+	"os"
+
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/version"
+)
+
+// BackfillItem is the changelog of a historical release
+type BackfillItem struct {
+	Tag         string
+	PreviousTag string
+	Path        string
+	// Changelog is nil when the file exists and is not overwritten
+	Changelog *Changelog
+	// Exists is true when the changelog file is already there
+	Exists bool
+}
+
+// PlanBackfill generates a changelog for every release tag of the package,
+// oldest first. Final releases list the changes since the previous final
+// release, pre-releases the changes since the tag before them. Each
+// changelog is dated with its tag. Changelogs are generated from the commit
+// history, fragments and merge requests are not used. Existing changelog
+// files are only generated again when overwrite is set.
+func PlanBackfill(pkg Package, gen GenerateOptions, overwrite bool) ([]BackfillItem, error) {
+	// one listing has the versions and dates of every tag
+	tags, err := git.Tags(git.TagFilter{Prefix: pkg.TagPrefix})
+	if err != nil {
+		return nil, err
+	}
+
+	type release struct {
+		tag git.Tag
+		v   version.Version
+	}
+	var releases []release
+	for _, t := range tags {
+		if v, prefix, ok := t.Version(); ok && prefix == pkg.TagPrefix {
+			releases = append(releases, release{t, v})
+		}
+	}
+
+	gen.Package = &pkg
+	gen.Source = config.SourceCommits
+	gen.FragmentDir = ""
+	gen.Forge = nil
+
+	var items []BackfillItem
+	previous, previousFinal := "", ""
+	for _, r := range releases {
+		from := previousFinal
+		if r.v.IsPrerelease() {
+			from = previous
+		}
+
+		item := BackfillItem{
+			Tag:         r.tag.Name,
+			PreviousTag: from,
+			Path:        pkg.ChangelogPath(r.v),
+		}
+		_, err := os.Stat(item.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		item.Exists = err == nil
+
+		if !item.Exists || overwrite {
+			gen.From = from
+			gen.To = r.tag.Name
+			gen.Version = r.tag.Name
+			gen.Date = r.tag.Date.Format("2006-01-02")
+			item.Changelog, err = GenerateChangelog(gen)
+			if err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, item)
+
+		previous = r.tag.Name
+		if !r.v.IsPrerelease() {
+			previousFinal = r.tag.Name
+		}
+	}
+
+	return items, nil
+}
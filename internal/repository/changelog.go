@@ -4,19 +4,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 )
 
-// order is version -> date -> image
-const changelogTemplate = `
+// order is version -> date -> link -> image
+const changelogHeader = `
 <div align="center">
     <h1>[%s] - %s</h1>
-  <a href="">
+  <a href="%s">
     <img src="%s" alt="Changelog Image" width="150" />
   </a>
 </div>
+`
 
+// order is version -> date -> link -> image
+const changelogTemplate = changelogHeader + `
 ## Added
 - 
 
@@ -56,7 +60,7 @@ func CreateChangelog(filepath, version, date, image_src string) error {
 	}
 	defer file.Close()
 
-	content := fmt.Sprintf(changelogTemplate, version, date, image_src)
+	content := fmt.Sprintf(changelogTemplate, version, date, "", image_src)
 	_, err = file.WriteString(content)
 	if err != nil {
 		return fmt.Errorf("error writing to changelog file: %w", err)
@@ -75,6 +79,10 @@ func filepathDir(path string) string {
 	return dir
 }
 
+// linkDefinitionRE matches markdown link reference definitions like
+// "[v1.2.0]: https://..."
+var linkDefinitionRE = regexp.MustCompile(`^\[[^\]]+\]:\s+\S+`)
+
 func cleanChangelog(content string) string {
 	lines := strings.Split(content, "\n")
 	var result []string
@@ -83,6 +91,17 @@ func cleanChangelog(content string) string {
 	validSection := false
 
 	for _, line := range lines {
+		if linkDefinitionRE.MatchString(line) {
+			// footer links never belong to a section
+			if validSection {
+				result = append(result, currentSection...)
+			}
+			currentSection = nil
+			validSection = false
+			result = append(result, line)
+			continue
+		}
+
 		if strings.HasPrefix(line, "## ") {
 			// flush previous section if valid
 			if validSection {
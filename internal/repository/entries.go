@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/conventional"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// Changelog sections, in the order of changelogTemplate
+const (
+	SectionAdded      = "Added"
+	SectionChanged    = "Changed"
+	SectionFixed      = "Fixed"
+	SectionRemoved    = "Removed"
+	SectionDeprecated = "Deprecated"
+	SectionSecurity   = "Security"
+)
+
+// Sections lists every changelog section in render order
+var Sections = []string{
+	SectionAdded,
+	SectionChanged,
+	SectionFixed,
+	SectionRemoved,
+	SectionDeprecated,
+	SectionSecurity,
+}
+
+// typeSections maps conventional commit types to changelog sections.
+// Types mapped to "" are left out of the changelog.
+var typeSections = map[string]string{
+	"feat":       SectionAdded,
+	"add":        SectionAdded,
+	"fix":        SectionFixed,
+	"perf":       SectionChanged,
+	"refactor":   SectionChanged,
+	"change":     SectionChanged,
+	"remove":     SectionRemoved,
+	"revert":     SectionRemoved,
+	"deprecate":  SectionDeprecated,
+	"security":   SectionSecurity,
+	"sec":        SectionSecurity,
+	"chore":      "",
+	"ci":         "",
+	"build":      "",
+	"docs":       "",
+	"style":      "",
+	"test":       "",
+	"tests":      "",
+	"release":    "",
+	"changelog":  "",
+	"dependabot": "",
+}
+
+// CommitTypes returns the conventional commit types the changelog knows,
+// sorted
+func CommitTypes() []string {
+	return slices.Sorted(maps.Keys(typeSections))
+}
+
+// breakingMarker starts the line of a breaking change
+const breakingMarker = "**BREAKING:**"
+
+// Entry is a single line in a changelog section
+type Entry struct {
+	Section     string
+	Description string
+	Scope       string
+	Breaking    bool
+	Hash        string
+	Author      string
+	References  []Reference
+	// MergeRequest is the "#12" or "!12" the entry was merged with, if known
+	MergeRequest    string
+	MergeRequestURL string
+	// Raw entries were read back from a changelog file, their description is
+	// markdown that is written out unchanged
+	Raw bool
+}
+
+// SectionForType returns the changelog section for a commit type, and false
+// when commits of that type are excluded from the changelog
+func SectionForType(commitType string) (string, bool) {
+	section, ok := typeSections[commitType]
+	if !ok {
+		return SectionChanged, true
+	}
+	return section, section != ""
+}
+
+// EntriesFromCommits turns commits into changelog entries, skipping merge
+// commits and types that do not belong in a changelog
+func EntriesFromCommits(commits []git.LogEntry) []Entry {
+	var entries []Entry
+
+	for _, c := range commits {
+		if strings.HasPrefix(c.Subject, "Merge ") {
+			continue
+		}
+
+		// a fragment describes the change instead
+		if hasFragmentTrailer(c) {
+			continue
+		}
+		msg := conventional.Parse(c.Subject, c.Body)
+		section := SectionChanged
+		if msg.Conventional {
+			s, ok := SectionForType(msg.Type)
+			if !ok && !msg.Breaking {
+				continue
+			}
+			if ok {
+				section = s
+			}
+		}
+
+		entries = append(entries, Entry{
+			Section:     section,
+			Description: msg.Description,
+			Scope:       msg.Scope,
+			Breaking:    msg.Breaking,
+			Hash:        c.Hash,
+			Author:      c.AuthorName,
+		})
+	}
+
+	return entries
+}
+
+// hasFragmentTrailer reports whether a commit links the fragment describing it
+func hasFragmentTrailer(c git.LogEntry) bool {
+	_, ok := conventional.Parse(c.Subject, c.Body).Footer(FragmentTrailer)
+	return ok
+}
+
+// Changelog is the content of a single release changelog file
+type Changelog struct {
+	Version string
+	Date    string
+	Image   string
+	Entries []Entry
+	// PreviousVersion is the tag the changelog starts from, if any
+	PreviousVersion string
+	// CompareURL links the header and footer to the changes in this release
+	CompareURL string
+	// Linker turns references into links, nil disables linking
+	Linker *ReferenceLinker
+	// GroupByReference nests entries under the first reference they mention
+	GroupByReference bool
+	// Fragments are the fragment files whose entries were added
+	Fragments []string
+}
+
+// AttachReferences fills in the references of every entry using the linker
+func (c *Changelog) AttachReferences() {
+	if c.Linker == nil {
+		return
+	}
+	for i := range c.Entries {
+		if c.Entries[i].Raw {
+			continue
+		}
+		c.Entries[i].References = c.Linker.Find(c.Entries[i].Description)
+	}
+}
+
+// String renders the changelog using the same layout as changelogTemplate,
+// leaving out empty sections
+func (c *Changelog) String() string {
+	header := fmt.Sprintf(changelogHeader, c.Version, c.Date, c.CompareURL, c.Image)
+	return strings.TrimSpace(header+"\n"+c.Body()) + "\n"
+}
+
+// Body renders the sections and footer without the HTML header block
+func (c *Changelog) Body() string {
+	var sb strings.Builder
+
+	for _, section := range Sections {
+		var entries []Entry
+		for _, e := range c.Entries {
+			if e.Section == section {
+				entries = append(entries, e)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\n## %s\n", section)
+		if c.GroupByReference {
+			c.writeGrouped(&sb, entries)
+		} else {
+			for _, e := range entries {
+				fmt.Fprintf(&sb, "- %s\n", c.entryLine(e))
+			}
+		}
+	}
+
+	// Keep a Changelog style footer, this also links the "[version]" heading
+	if c.CompareURL != "" {
+		fmt.Fprintf(&sb, "\n[%s]: %s\n", c.Version, c.CompareURL)
+	}
+
+	return strings.TrimSpace(sb.String()) + "\n"
+}
+
+// writeGrouped writes entries sharing a reference as a nested list under it
+func (c *Changelog) writeGrouped(sb *strings.Builder, entries []Entry) {
+	var order []string
+	groups := map[string][]Entry{}
+	refs := map[string]Reference{}
+
+	for _, e := range entries {
+		key := ""
+		if len(e.References) > 0 {
+			key = e.References[0].Text
+			refs[key] = e.References[0]
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	for _, key := range order {
+		group := groups[key]
+		if key == "" || len(group) == 1 {
+			for _, e := range group {
+				fmt.Fprintf(sb, "- %s\n", c.entryLine(e))
+			}
+			continue
+		}
+
+		heading := key
+		if ref := refs[key]; ref.URL != "" {
+			heading = fmt.Sprintf("[%s](%s)", ref.Text, ref.URL)
+		}
+		fmt.Fprintf(sb, "- %s\n", heading)
+		for _, e := range group {
+			fmt.Fprintf(sb, "  - %s\n", c.entryLine(e))
+		}
+	}
+}
+
+func (c *Changelog) entryLine(e Entry) string {
+	if e.Raw {
+		// the markdown is kept as written, only the marker follows Breaking,
+		// which the interactive review may have toggled
+		rest, marked := strings.CutPrefix(e.Description, breakingMarker)
+		switch {
+		case e.Breaking && !marked:
+			return breakingMarker + " " + e.Description
+		case !e.Breaking && marked:
+			return strings.TrimLeft(rest, " ")
+		}
+		return e.Description
+	}
+
+	line := e.Description
+	if c.Linker != nil {
+		line = c.Linker.Linkify(line)
+	}
+	if e.Scope != "" {
+		line = fmt.Sprintf("**%s:** %s", e.Scope, line)
+	}
+	if e.Breaking {
+		line = breakingMarker + " " + line
+	}
+	if e.MergeRequest != "" {
+		ref := e.MergeRequest
+		if e.MergeRequestURL != "" {
+			ref = fmt.Sprintf("[%s](%s)", e.MergeRequest, e.MergeRequestURL)
+		}
+		line = fmt.Sprintf("%s (%s)", line, ref)
+		if e.Author != "" {
+			line += " by @" + e.Author
+		}
+	}
+	return line
+}
@@ -0,0 +1,25 @@
+package repository
+
+import "testing"
+
+func TestEntryLineRawBreaking(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+		want  string
+	}{
+		{"unchanged", Entry{Description: "add x ([abc1234](https://example.com))", Raw: true}, "add x ([abc1234](https://example.com))"},
+		{"marked", Entry{Description: "**BREAKING:** drop y", Breaking: true, Raw: true}, "**BREAKING:** drop y"},
+		{"toggled on", Entry{Description: "**api:** drop y", Breaking: true, Raw: true}, "**BREAKING:** **api:** drop y"},
+		{"toggled off", Entry{Description: "**BREAKING:** drop y", Raw: true}, "drop y"},
+		{"generated", Entry{Description: "drop y", Scope: "api", Breaking: true}, "**BREAKING:** **api:** drop y"},
+	}
+	c := &Changelog{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.entryLine(tt.entry); got != tt.want {
+				t.Errorf("entryLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
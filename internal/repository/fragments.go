@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// FragmentDirName is the directory below a changelog dir holding the
+// fragments of unreleased changes
+const FragmentDirName = "unreleased"
+
+// FragmentTrailer links a commit to the fragment describing it, so the commit
+// is not listed a second time
+const FragmentTrailer = "Changelog-Fragment"
+
+// maxFragmentName limits the length of generated fragment file names
+const maxFragmentName = 50
+
+var slugRE = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Fragment is a hand written changelog snippet for an unreleased change
+type Fragment struct {
+	Path    string
+	Entries []Entry
+}
+
+// FragmentDir returns the directory the package's fragments are written to
+func (p Package) FragmentDir() string {
+	return filepath.Join(p.ChangelogDir, FragmentDirName)
+}
+
+// ReadFragments reads every fragment in dir sorted by file name. A missing
+// directory means there are no fragments.
+func ReadFragments(dir string) ([]Fragment, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+
+	var fragments []Fragment
+	for _, path := range paths {
+		c, err := ReadChangelog(path)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, Fragment{Path: path, Entries: c.Entries})
+	}
+	return fragments, nil
+}
+
+// WriteFragment renders the entries into a new fragment in dir named after
+// name and returns its path
+func WriteFragment(dir, name string, entries []Entry, linker *ReferenceLinker) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	slug := strings.Trim(slugRE.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(slug) > maxFragmentName {
+		slug = strings.TrimRight(slug[:maxFragmentName], "-")
+	}
+	if slug == "" {
+		slug = "change"
+	}
+
+	path := filepath.Join(dir, slug+".md")
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", slug, n))
+	}
+
+	c := &Changelog{Entries: entries, Linker: linker}
+	c.AttachReferences()
+	if err := os.WriteFile(path, []byte(c.Body()), 0644); err != nil {
+		return "", fmt.Errorf("error writing changelog fragment: %w", err)
+	}
+	return path, nil
+}
+
+// RemoveFragments deletes consumed fragments from the working tree and the
+// index, so the release commit records their removal
+func RemoveFragments(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	if err := git.RemoveFiles(paths); err != nil {
+		return err
+	}
+	// fragments that were never committed are left alone by git
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nick-ccc/CLIborg/internal/api"
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// GenerateOptions controls how a changelog is built from the commit history
+type GenerateOptions struct {
+	Version string
+	// Date defaults to today
+	Date  string
+	Image string
+	// From and To bound the commit range, To defaults to HEAD. From defaults
+	// to the latest tag unless a Package is set, in which case an empty From
+	// means the package has no release yet.
+	From string
+	To   string
+	// Remote is used to detect the forge for links, defaults to the base remote
+	Remote string
+	// References are the patterns to link, nil uses DefaultReferencePatterns
+	References       []ReferencePattern
+	GroupByReference bool
+	// Package limits the changelog to commits touching the package files
+	Package *Package
+	// Source is config.SourceCommits or config.SourceMergeRequests
+	Source string
+	// Forge fetches merge requests when they are the source
+	Forge         api.Forge
+	MergeRequests MergeRequestOptions
+	// FragmentDir adds the entries of the fragments in this directory, ""
+	// leaves fragments out
+	FragmentDir string
+}
+
+// GenerateOptionsFromConfig returns the options configured for the repository
+func GenerateOptionsFromConfig(cfg *config.Config) (GenerateOptions, error) {
+	patterns, err := ReferencePatternsFromConfig(cfg.Changelog.References)
+	if err != nil {
+		return GenerateOptions{}, err
+	}
+
+	opts := GenerateOptions{
+		Image:            cfg.Changelog.Image,
+		References:       patterns,
+		GroupByReference: cfg.Changelog.GroupByReference,
+		Source:           cfg.Changelog.Source,
+	}
+
+	if cfg.Changelog.ExcludeLabels != nil {
+		// configured exclude labels extend the defaults, like labels do
+		opts.MergeRequests.ExcludeLabels = slices.Clone(cfg.Changelog.ExcludeLabels)
+		for _, label := range DefaultExcludeLabels {
+			if !hasLabel(opts.MergeRequests.ExcludeLabels, []string{label}) {
+				opts.MergeRequests.ExcludeLabels = append(opts.MergeRequests.ExcludeLabels, label)
+			}
+		}
+	}
+
+	if cfg.Changelog.Labels != nil {
+		opts.MergeRequests.Labels = map[string]string{}
+		for label, section := range cfg.Changelog.Labels {
+			if !slices.Contains(Sections, section) {
+				return GenerateOptions{}, fmt.Errorf("label %q maps to unknown section %q, expected one of %v", label, section, Sections)
+			}
+			opts.MergeRequests.Labels[strings.ToLower(label)] = section
+		}
+		// configured labels extend the defaults
+		for label, section := range DefaultLabelSections {
+			if _, ok := opts.MergeRequests.Labels[label]; !ok {
+				opts.MergeRequests.Labels[label] = section
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// GenerateChangelog builds a changelog for the commits in the options range
+func GenerateChangelog(opts GenerateOptions) (*Changelog, error) {
+	if opts.Date == "" {
+		opts.Date = time.Now().Format("2006-01-02")
+	}
+	if opts.Remote == "" {
+		opts.Remote = git.BaseRemoteName()
+	}
+	if opts.From == "" && opts.Package == nil {
+		// no tags yet means the whole history goes into the changelog
+		opts.From, _ = git.LatestTag()
+	}
+
+	scoped := opts.Package != nil && !opts.Package.IsRoot()
+	commits, err := git.Log(opts.From, opts.To, scoped)
+	if err != nil {
+		return nil, fmt.Errorf("error reading commit history: %w", err)
+	}
+	if scoped {
+		commits = AttributeCommits([]Package{*opts.Package}, commits)[opts.Package.Name]
+	}
+
+	// links are best effort, a missing or unknown remote only disables them
+	project, _ := git.RemoteProject(opts.Remote)
+
+	var entries []Entry
+	switch opts.Source {
+	case "", config.SourceCommits:
+		entries = EntriesFromCommits(commits)
+	case config.SourceMergeRequests:
+		entries, err = mergeRequestEntries(opts, commits, project)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown changelog source: %s", opts.Source)
+	}
+
+	var fragments []string
+	if opts.FragmentDir != "" {
+		found, err := ReadFragments(opts.FragmentDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			entries = append(entries, f.Entries...)
+			fragments = append(fragments, f.Path)
+		}
+	}
+
+	c := &Changelog{
+		Version:          opts.Version,
+		Date:             opts.Date,
+		Image:            opts.Image,
+		Entries:          entries,
+		PreviousVersion:  opts.From,
+		CompareURL:       CompareLink(project, opts.From, opts.Version),
+		Linker:           NewReferenceLinker(project, opts.References),
+		GroupByReference: opts.GroupByReference,
+		Fragments:        fragments,
+	}
+	c.AttachReferences()
+
+	return c, nil
+}
+
+// WriteChangelog renders the changelog into the file at path
+func WriteChangelog(path string, c *Changelog) error {
+	dir := filepathDir(path)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	err = os.WriteFile(path, []byte(c.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing to changelog file: %w", err)
+	}
+	return nil
+}
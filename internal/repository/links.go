@@ -0,0 +1,15 @@
+package repository
+
+import "github.com/nick-ccc/CLIborg/internal/git"
+
+// CompareLink returns the URL showing the changes between two releases.
+// Without a previous release the link points at the new tag itself.
+func CompareLink(project *git.Project, previous, version string) string {
+	if project == nil || version == "" {
+		return ""
+	}
+	if previous == "" {
+		return project.TagURL(version)
+	}
+	return project.CompareURL(previous, version)
+}
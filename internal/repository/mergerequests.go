@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/api"
+	"github.com/nick-ccc/CLIborg/internal/conventional"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// DefaultLabelSections maps common merge request labels to sections
+var DefaultLabelSections = map[string]string{
+	"feature":     SectionAdded,
+	"enhancement": SectionAdded,
+	"bug":         SectionFixed,
+	"fix":         SectionFixed,
+	"removal":     SectionRemoved,
+	"deprecation": SectionDeprecated,
+	"security":    SectionSecurity,
+}
+
+// DefaultExcludeLabels leave a merge request out of the changelog
+var DefaultExcludeLabels = []string{"skip-changelog"}
+
+// breakingLabels mark a merge request as a breaking change
+var breakingLabels = []string{"breaking", "breaking-change", "breaking change"}
+
+// MergeRequestOptions configures entries built from merge requests
+type MergeRequestOptions struct {
+	// Labels maps labels to sections, nil uses DefaultLabelSections.
+	// GenerateOptionsFromConfig adds the defaults to configured labels.
+	Labels map[string]string
+	// ExcludeLabels skips merge requests, nil uses DefaultExcludeLabels.
+	// GenerateOptionsFromConfig adds the defaults to configured labels.
+	ExcludeLabels []string
+	// Provider decides how merge request numbers are written
+	Provider git.Provider
+}
+
+// EntriesFromMergeRequests turns merged merge requests into changelog
+// entries. The section comes from the first mapped label, then from a
+// Conventional Commit style title, and defaults to Changed.
+func EntriesFromMergeRequests(mrs []api.MergeRequest, opts MergeRequestOptions) []Entry {
+	labels := opts.Labels
+	if labels == nil {
+		labels = DefaultLabelSections
+	}
+	exclude := opts.ExcludeLabels
+	if exclude == nil {
+		exclude = DefaultExcludeLabels
+	}
+
+	prefix := "#"
+	if opts.Provider == git.ProviderGitLab {
+		prefix = "!"
+	}
+
+	var entries []Entry
+	for _, mr := range mrs {
+		if hasLabel(mr.Labels, exclude) {
+			continue
+		}
+
+		msg := conventional.Parse(mr.Title, "")
+		section, ok := labelSection(mr.Labels, labels)
+		if !ok {
+			section = SectionChanged
+			if msg.Conventional {
+				s, include := SectionForType(msg.Type)
+				if !include && !msg.Breaking {
+					continue
+				}
+				if include {
+					section = s
+				}
+			}
+		}
+
+		entries = append(entries, Entry{
+			Section:         section,
+			Description:     msg.Description,
+			Scope:           msg.Scope,
+			Breaking:        msg.Breaking || hasLabel(mr.Labels, breakingLabels),
+			Hash:            mr.MergeCommitSHA,
+			Author:          mr.Author,
+			MergeRequest:    prefix + strconv.Itoa(mr.Number),
+			MergeRequestURL: mr.URL,
+		})
+	}
+
+	return entries
+}
+
+// mergeRequestEntries fetches the merge requests whose merge commit is one of
+// the commits and turns them into entries, newest first
+func mergeRequestEntries(opts GenerateOptions, commits []git.LogEntry, project *git.Project) ([]Entry, error) {
+	if opts.Forge == nil {
+		return nil, fmt.Errorf("a forge API client is needed to build the changelog from merge requests")
+	}
+	if project == nil {
+		return nil, fmt.Errorf("could not determine the project of remote %s", opts.Remote)
+	}
+
+	mrs, err := opts.Forge.MergedMergeRequestsSinceTag(context.Background(), project.Path, opts.From, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// only merge requests landing in the commit range belong to this release
+	byHash := map[string]git.LogEntry{}
+	for _, c := range commits {
+		byHash[c.Hash] = c
+	}
+	var inRange []api.MergeRequest
+	for _, mr := range mrs {
+		if mr.MergeCommitSHA == "" {
+			slog.Warn("merge request has no merge commit, it is left out of the changelog", "number", mr.Number, "title", mr.Title)
+			continue
+		}
+		c, ok := byHash[mr.MergeCommitSHA]
+		if !ok {
+			continue
+		}
+		// a fragment describes the change instead
+		if mergedWithFragment(c) {
+			continue
+		}
+		if mr.URL == "" {
+			mr.URL = project.MergeRequestURL(strconv.Itoa(mr.Number))
+		}
+		inRange = append(inRange, mr)
+	}
+	slices.Reverse(inRange)
+
+	mrOpts := opts.MergeRequests
+	mrOpts.Provider = project.Provider
+	return EntriesFromMergeRequests(inRange, mrOpts), nil
+}
+
+// mergedWithFragment reports whether the commit a merge request landed as, or
+// for a merge commit one of the merged commits, links a fragment
+func mergedWithFragment(c git.LogEntry) bool {
+	if hasFragmentTrailer(c) {
+		return true
+	}
+	if !strings.HasPrefix(c.Subject, "Merge ") {
+		return false
+	}
+	// best effort, the merge request is listed when its commits are unknown
+	merged, err := git.Log(c.Hash+"^1", c.Hash, false)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(merged, hasFragmentTrailer)
+}
+
+func labelSection(labels []string, sections map[string]string) (string, bool) {
+	for _, l := range labels {
+		if s, ok := sections[strings.ToLower(l)]; ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+func hasLabel(labels, wanted []string) bool {
+	for _, l := range labels {
+		for _, w := range wanted {
+			if strings.EqualFold(l, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
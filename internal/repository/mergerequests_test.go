@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nick-ccc/CLIborg/internal/api"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// fakeForge returns fixed merge requests
+type fakeForge struct {
+	api.Forge
+	mrs []api.MergeRequest
+}
+
+func (f fakeForge) MergedMergeRequestsSinceTag(context.Context, string, string, string) ([]api.MergeRequest, error) {
+	return f.mrs, nil
+}
+
+func TestMergeRequestEntries(t *testing.T) {
+	commits := []git.LogEntry{
+		{Hash: "ccc", Subject: "feat: c (#3)"},
+		{Hash: "bbb", Subject: "fix: b (#2)", Body: "Changelog-Fragment: changelogs/unreleased/fix-b.md"},
+		{Hash: "aaa", Subject: "feat: a (#1)"},
+	}
+	forge := fakeForge{mrs: []api.MergeRequest{
+		{Number: 1, Title: "feat: a", MergeCommitSHA: "aaa", URL: "https://github.com/o/n/pull/1"},
+		{Number: 2, Title: "fix: b", MergeCommitSHA: "bbb", URL: "https://github.com/o/n/pull/2"},
+		{Number: 3, Title: "feat: c", MergeCommitSHA: "ccc", URL: "https://github.com/o/n/pull/3"},
+		{Number: 4, Title: "feat: elsewhere", MergeCommitSHA: "ddd"},
+	}}
+	project := &git.Project{Provider: git.ProviderGitHub, Scheme: "https", Host: "github.com", Path: "o/n"}
+
+	entries, err := mergeRequestEntries(GenerateOptions{Forge: forge}, commits, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.MergeRequest+" "+e.Description)
+	}
+	// newest first, the fragment describes #2 and #4 is not in the range
+	want := []string{"#3 c", "#1 a"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mergeRequestEntries() = %v, want %v", got, want)
+	}
+}
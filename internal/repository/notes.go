@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/version"
+)
+
+// CombinedChangelogName is the Keep a Changelog style file listing every
+// release in one document
+const CombinedChangelogName = "CHANGELOG.md"
+
+// ErrVersionNotFound indicates that no changelog describes a version
+var ErrVersionNotFound = errors.New("no changelog found")
+
+// headerBlockRE matches the HTML block written from changelogHeader
+var headerBlockRE = regexp.MustCompile(`(?s)^\s*<div align="center">.*?</div>\s*`)
+
+// headingRE matches a markdown heading, capturing its level and text
+var headingRE = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*$`)
+
+// headingVersionRE reads the version and optional date from a combined
+// changelog heading such as "[1.2.0] - 2025-01-31"
+var headingVersionRE = regexp.MustCompile(`^\[?([^\]\s]+)\]?(?:\s+-\s+(\S+))?`)
+
+// inlineLinkRE, emphasisRE and codeRE match the markdown PlainText removes
+var (
+	inlineLinkRE = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	emphasisRE   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	codeRE       = regexp.MustCompile("`([^`]*)`")
+)
+
+// ReleaseNotes are the changes of a single release read back from its
+// changelog
+type ReleaseNotes struct {
+	Version    string
+	Date       string
+	CompareURL string
+	// Path is the file the notes were read from
+	Path string
+	// Header is the HTML header block of a per-version file, if any
+	Header string
+	// Markdown holds the sections without the header or version heading
+	Markdown string
+	Entries  []Entry
+}
+
+// NotesSection is a changelog section with the markdown of its entries
+type NotesSection struct {
+	Name    string
+	Entries []string
+}
+
+// FindReleaseNotes reads the notes of a release of the package. The version
+// may be given with or without the leading "v" or the package tag prefix.
+// The per-version changelog file is preferred, otherwise the release section
+// of a combined CHANGELOG.md in the changelog dir or the current directory is
+// used.
+func FindReleaseNotes(pkg Package, ref string) (*ReleaseNotes, error) {
+	v, err := version.Parse(strings.TrimPrefix(ref, pkg.TagPrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	path := pkg.ChangelogPath(v)
+	content, err := os.ReadFile(path)
+	if err == nil {
+		return versionFileNotes(path, string(content)), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading file: %s: %w", path, err)
+	}
+
+	candidates := []string{filepath.Join(pkg.ChangelogDir, CombinedChangelogName)}
+	if pkg.IsRoot() {
+		candidates = append(candidates, CombinedChangelogName)
+	}
+	for _, path := range candidates {
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading file: %s: %w", path, err)
+		}
+		if notes, ok := combinedNotes(path, string(content), v); ok {
+			return notes, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w for %s", ErrVersionNotFound, pkg.Tag(v))
+}
+
+// versionFileNotes splits a per-version changelog into its header and notes
+func versionFileNotes(path, content string) *ReleaseNotes {
+	c := ParseChangelog(content)
+	notes := &ReleaseNotes{
+		Version:    c.Version,
+		Date:       c.Date,
+		CompareURL: c.CompareURL,
+		Path:       path,
+		Markdown:   strings.TrimSpace(content) + "\n",
+		Entries:    c.Entries,
+	}
+	if loc := headerBlockRE.FindStringIndex(content); loc != nil {
+		notes.Header = strings.TrimSpace(content[:loc[1]]) + "\n"
+		notes.Markdown = strings.TrimSpace(content[loc[1]:]) + "\n"
+	}
+	return notes
+}
+
+// combinedNotes extracts the section of a version from a changelog listing
+// every release under its own heading
+func combinedNotes(path, content string, v version.Version) (*ReleaseNotes, bool) {
+	lines := strings.Split(content, "\n")
+
+	start, end, level := -1, len(lines), 0
+	notes := &ReleaseNotes{Path: path}
+	for i, line := range lines {
+		m := headingRE.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if start >= 0 {
+			// the next release heading ends the section
+			if len(m[1]) <= level {
+				end = i
+				break
+			}
+			continue
+		}
+		hm := headingVersionRE.FindStringSubmatch(m[2])
+		if hm == nil {
+			continue
+		}
+		if hv, err := version.Parse(hm[1]); err == nil && version.Compare(hv, v) == 0 {
+			start, level = i+1, len(m[1])
+			notes.Version = hm[1]
+			notes.Date = hm[2]
+		}
+	}
+	if start < 0 {
+		return nil, false
+	}
+	lines = lines[start:end]
+
+	// link definitions of every release sit at the end of the file
+	var body []string
+	for _, line := range lines {
+		if !linkDefinitionRE.MatchString(line) {
+			body = append(body, line)
+		}
+	}
+	notes.Markdown = strings.TrimSpace(strings.Join(body, "\n")) + "\n"
+
+	definition := regexp.MustCompile(`(?m)^\[` + regexp.QuoteMeta(notes.Version) + `\]:\s+(\S+)`)
+	if m := definition.FindStringSubmatch(content); m != nil {
+		notes.CompareURL = m[1]
+	}
+
+	// sections are one level below the release heading, ParseChangelog
+	// expects them at level two
+	sectionPrefix := strings.Repeat("#", level+1) + " "
+	var normalized []string
+	for _, line := range body {
+		if strings.HasPrefix(line, sectionPrefix) {
+			line = "## " + strings.TrimPrefix(line, sectionPrefix)
+		}
+		normalized = append(normalized, line)
+	}
+	notes.Entries = ParseChangelog(strings.Join(normalized, "\n")).Entries
+
+	return notes, true
+}
+
+// Sections groups the entries by section in changelog order
+func (n *ReleaseNotes) Sections() []NotesSection {
+	var sections []NotesSection
+	for _, name := range Sections {
+		var entries []string
+		for _, e := range n.Entries {
+			if e.Section == name {
+				entries = append(entries, e.Description)
+			}
+		}
+		if len(entries) > 0 {
+			sections = append(sections, NotesSection{Name: name, Entries: entries})
+		}
+	}
+	return sections
+}
+
+// PlainText renders changelog markdown as plain text: link targets, emphasis
+// and link definitions are dropped and headings become "Name:" lines
+func PlainText(markdown string) string {
+	var lines []string
+	for _, line := range strings.Split(markdown, "\n") {
+		if linkDefinitionRE.MatchString(line) {
+			continue
+		}
+		if m := headingRE.FindStringSubmatch(line); m != nil {
+			line = m[2] + ":"
+		}
+		lines = append(lines, StripMarkdown(line))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
+}
+
+// StripMarkdown removes inline links, emphasis and code spans from a line
+func StripMarkdown(s string) string {
+	s = inlineLinkRE.ReplaceAllString(s, "$1")
+	s = emphasisRE.ReplaceAllString(s, "$1$2")
+	return codeRE.ReplaceAllString(s, "$1")
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/version"
+)
+
+// Package is an independently versioned part of the repository. The root
+// package of a single module repository owns every file and has no prefix.
+type Package struct {
+	Name         string
+	Paths        []string
+	TagPrefix    string
+	ChangelogDir string
+}
+
+// PackagesFromConfig returns the configured packages, or a single root
+// package when the repository is not split into packages
+func PackagesFromConfig(cfg *config.Config) ([]Package, error) {
+	if len(cfg.Packages) == 0 {
+		return []Package{{ChangelogDir: cfg.Changelog.Dir}}, nil
+	}
+
+	seen := map[string]bool{}
+	pkgs := make([]Package, 0, len(cfg.Packages))
+	for _, p := range cfg.Packages {
+		if p.Name == "" {
+			return nil, fmt.Errorf("package with paths %v has no name", p.Paths)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("package %s is defined more than once", p.Name)
+		}
+		seen[p.Name] = true
+
+		if len(p.Paths) == 0 {
+			return nil, fmt.Errorf("package %s has no paths", p.Name)
+		}
+		for _, glob := range p.Paths {
+			if _, err := path.Match(strings.ReplaceAll(glob, "**", "*"), ""); err != nil {
+				return nil, fmt.Errorf("package %s has an invalid path %q: %w", p.Name, glob, err)
+			}
+		}
+
+		prefix := p.TagPrefix
+		if prefix == "" {
+			prefix = p.Name + "/"
+		}
+		dir := p.ChangelogDir
+		if dir == "" {
+			dir = filepath.Join(cfg.Changelog.Dir, p.Name)
+		}
+
+		pkgs = append(pkgs, Package{
+			Name:         p.Name,
+			Paths:        p.Paths,
+			TagPrefix:    prefix,
+			ChangelogDir: dir,
+		})
+	}
+
+	return pkgs, nil
+}
+
+// FindPackage returns the package with the given name
+func FindPackage(pkgs []Package, name string) (Package, error) {
+	for _, p := range pkgs {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Package{}, fmt.Errorf("unknown package: %s", name)
+}
+
+// IsRoot reports whether the package covers the whole repository
+func (p Package) IsRoot() bool {
+	return len(p.Paths) == 0
+}
+
+// Owns reports whether a repository relative file belongs to the package
+func (p Package) Owns(file string) bool {
+	if p.IsRoot() {
+		return true
+	}
+	file = filepath.ToSlash(file)
+	for _, glob := range p.Paths {
+		if matchGlob(glob, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Touches reports whether a commit changed any file owned by the package.
+// The commit must have been read with file names.
+func (p Package) Touches(commit git.LogEntry) bool {
+	if p.IsRoot() {
+		return true
+	}
+	for _, f := range commit.Files {
+		if p.Owns(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tag returns the tag name for a version of the package
+func (p Package) Tag(v version.Version) string {
+	return p.TagPrefix + v.String()
+}
+
+// ParseTag returns the version encoded in a tag of the package
+func (p Package) ParseTag(tag string) (version.Version, bool) {
+	if !strings.HasPrefix(tag, p.TagPrefix) {
+		return version.Version{}, false
+	}
+	v, err := version.Parse(strings.TrimPrefix(tag, p.TagPrefix))
+	if err != nil {
+		return version.Version{}, false
+	}
+	return v, true
+}
+
+// ChangelogPath returns the changelog file for a version of the package
+func (p Package) ChangelogPath(v version.Version) string {
+	return filepath.Join(p.ChangelogDir, fmt.Sprintf("CHANGELOG-%s.md", v))
+}
+
+// AttributeCommits groups commits by the packages whose files they touch.
+// A commit touching several packages is attributed to each of them.
+func AttributeCommits(pkgs []Package, commits []git.LogEntry) map[string][]git.LogEntry {
+	attributed := map[string][]git.LogEntry{}
+	for _, c := range commits {
+		for _, p := range pkgs {
+			if p.Touches(c) {
+				attributed[p.Name] = append(attributed[p.Name], c)
+			}
+		}
+	}
+	return attributed
+}
+
+// matchGlob matches slash separated paths where "**" spans any number of
+// directories and every other segment follows path.Match. A pattern matching
+// a directory also matches everything below it.
+func matchGlob(pattern, name string) bool {
+	segments := strings.Split(strings.TrimSuffix(pattern, "/"), "/")
+	return matchSegments(append(segments, "**"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// headerTitleRE reads the version and date from the changelogHeader title
+var headerTitleRE = regexp.MustCompile(`<h1>\[([^\]]+)\] - ([^<]*)</h1>`)
+
+// headerLinkRE reads the compare link from the changelogHeader anchor
+var headerLinkRE = regexp.MustCompile(`<a href="([^"]*)">`)
+
+// headerImageRE reads the image from the changelogHeader
+var headerImageRE = regexp.MustCompile(`<img src="([^"]*)"`)
+
+// ParseChangelog reads a rendered changelog back. Entries keep their markdown
+// as written and are marked Raw so they are not linked a second time.
+func ParseChangelog(content string) *Changelog {
+	c := &Changelog{}
+
+	if m := headerTitleRE.FindStringSubmatch(content); m != nil {
+		c.Version = m[1]
+		c.Date = strings.TrimSpace(m[2])
+	}
+	if m := headerLinkRE.FindStringSubmatch(content); m != nil {
+		c.CompareURL = m[1]
+	}
+	if m := headerImageRE.FindStringSubmatch(content); m != nil {
+		c.Image = m[1]
+	}
+
+	section := ""
+	var pending *Entry
+	grouped := false
+
+	flush := func() {
+		if pending != nil && !grouped {
+			c.Entries = append(c.Entries, *pending)
+		}
+		pending = nil
+		grouped = false
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			flush()
+			section = strings.TrimSpace(strings.TrimPrefix(line, "## "))
+			if !slices.Contains(Sections, section) {
+				section = ""
+			}
+		case section == "":
+			continue
+		case strings.TrimSpace(line) == "-":
+			// an empty bullet is a placeholder of changelogTemplate
+			flush()
+		case strings.HasPrefix(line, "- "):
+			flush()
+			pending = parsedEntry(section, strings.TrimPrefix(line, "- "))
+		case strings.HasPrefix(strings.TrimLeft(line, " \t"), "- ") && pending != nil:
+			// nested entries replace the reference heading they are grouped under
+			grouped = true
+			nested := strings.TrimPrefix(strings.TrimLeft(line, " \t"), "- ")
+			c.Entries = append(c.Entries, *parsedEntry(section, nested))
+		case strings.TrimSpace(line) == "":
+			continue
+		default:
+			flush()
+			section = ""
+		}
+	}
+	flush()
+
+	return c
+}
+
+// ReadChangelog parses the changelog file at path
+func ReadChangelog(path string) (*Changelog, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %s: %w", path, err)
+	}
+	return ParseChangelog(string(content)), nil
+}
+
+func parsedEntry(section, text string) *Entry {
+	text = strings.TrimSpace(text)
+	return &Entry{
+		Section:     section,
+		Description: text,
+		Breaking:    strings.HasPrefix(text, breakingMarker),
+		Raw:         true,
+	}
+}
+
+// MergeEntries appends the entries of every list, dropping duplicates of an
+// entry already seen in the same section
+func MergeEntries(lists ...[]Entry) []Entry {
+	var merged []Entry
+	seen := map[string]bool{}
+	for _, entries := range lists {
+		for _, e := range entries {
+			key := e.Section + "\x00" + e.Description
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, e)
+		}
+	}
+	return merged
+}
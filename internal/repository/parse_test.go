@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestParseChangelog(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		wantVersion    string
+		wantDate       string
+		wantCompareURL string
+		want           []Entry
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+		{
+			name: "header and sections",
+			content: `
+<div align="center">
+    <h1>[v1.2.0] - 2025-01-31</h1>
+  <a href="https://github.com/o/n/compare/v1.1.0...v1.2.0">
+    <img src="img.png" alt="Changelog Image" width="150" />
+  </a>
+</div>
+
+## Added
+- add x ([abc1234](https://github.com/o/n/commit/abc1234))
+- **BREAKING:** drop y
+
+## Fixed
+- fix z
+
+[v1.2.0]: https://github.com/o/n/compare/v1.1.0...v1.2.0
+`,
+			wantVersion:    "v1.2.0",
+			wantDate:       "2025-01-31",
+			wantCompareURL: "https://github.com/o/n/compare/v1.1.0...v1.2.0",
+			want: []Entry{
+				{Section: SectionAdded, Description: "add x ([abc1234](https://github.com/o/n/commit/abc1234))", Raw: true},
+				{Section: SectionAdded, Description: "**BREAKING:** drop y", Breaking: true, Raw: true},
+				{Section: SectionFixed, Description: "fix z", Raw: true},
+			},
+		},
+		{
+			name:    "template placeholders",
+			content: "## Added\n- \n\n## Changed\n-\n\n## Fixed\n- fix z\n",
+			want: []Entry{
+				{Section: SectionFixed, Description: "fix z", Raw: true},
+			},
+		},
+		{
+			name: "grouped by reference",
+			content: `## Changed
+- [#12](https://github.com/o/n/issues/12)
+  - change a
+  - change b
+- change c
+`,
+			want: []Entry{
+				{Section: SectionChanged, Description: "change a", Raw: true},
+				{Section: SectionChanged, Description: "change b", Raw: true},
+				{Section: SectionChanged, Description: "change c", Raw: true},
+			},
+		},
+		{
+			name: "unknown sections and prose",
+			content: `## Notes
+- not an entry
+
+## Security
+- patch a
+
+Some closing words.
+- not an entry either
+`,
+			want: []Entry{
+				{Section: SectionSecurity, Description: "patch a", Raw: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ParseChangelog(tt.content)
+			if c.Version != tt.wantVersion || c.Date != tt.wantDate || c.CompareURL != tt.wantCompareURL {
+				t.Errorf("header = %q, %q, %q, want %q, %q, %q", c.Version, c.Date, c.CompareURL, tt.wantVersion, tt.wantDate, tt.wantCompareURL)
+			}
+			if len(c.Entries) != len(tt.want) {
+				t.Fatalf("ParseChangelog() entries = %+v, want %+v", c.Entries, tt.want)
+			}
+			for i, e := range c.Entries {
+				w := tt.want[i]
+				if e.Section != w.Section || e.Description != w.Description || e.Breaking != w.Breaking || e.Raw != w.Raw {
+					t.Errorf("entry %d = %+v, want %+v", i, e, w)
+				}
+			}
+		})
+	}
+}
+
+func TestParseChangelogRoundTrip(t *testing.T) {
+	c := &Changelog{
+		Version:    "v2.0.0",
+		Date:       "2025-02-01",
+		CompareURL: "https://gitlab.com/g/n/-/compare/v1.0.0...v2.0.0",
+		Image:      "img.png",
+		Entries: []Entry{
+			{Section: SectionAdded, Description: "add x", Raw: true},
+			{Section: SectionRemoved, Description: "**BREAKING:** remove y", Breaking: true, Raw: true},
+		},
+	}
+
+	parsed := ParseChangelog(c.String())
+	if parsed.Version != c.Version || parsed.Date != c.Date || parsed.CompareURL != c.CompareURL || parsed.Image != c.Image {
+		t.Errorf("header = %+v", parsed)
+	}
+	if len(parsed.Entries) != len(c.Entries) {
+		t.Fatalf("entries = %+v, want %+v", parsed.Entries, c.Entries)
+	}
+	for i, e := range parsed.Entries {
+		w := c.Entries[i]
+		if e.Section != w.Section || e.Description != w.Description || e.Breaking != w.Breaking || !e.Raw {
+			t.Errorf("entry %d = %+v, want %+v", i, e, w)
+		}
+	}
+	if parsed.String() != c.String() {
+		t.Errorf("rendering the parsed changelog changed it:\n%s\nwant:\n%s", parsed.String(), c.String())
+	}
+}
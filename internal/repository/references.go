@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nick-ccc/CLIborg/internal/config"
+	"github.com/nick-ccc/CLIborg/internal/git"
+)
+
+// ReferenceKind is the type of item a commit subject refers to
+type ReferenceKind string
+
+const (
+	ReferenceIssue        ReferenceKind = "issue"
+	ReferenceMergeRequest ReferenceKind = "merge_request"
+	ReferenceJira         ReferenceKind = "jira"
+)
+
+// ReferencePattern detects references in text. The first capture group of
+// Regexp is the id and the whole match is the text that gets linked.
+type ReferencePattern struct {
+	Kind   ReferenceKind
+	Regexp *regexp.Regexp
+	// URL overrides the provider link, "{id}" is replaced by the id
+	URL string
+}
+
+// DefaultReferencePatterns detects "#123" issues and "!45" merge requests
+var DefaultReferencePatterns = []ReferencePattern{
+	{Kind: ReferenceIssue, Regexp: regexp.MustCompile(`\B#(\d+)\b`)},
+	{Kind: ReferenceMergeRequest, Regexp: regexp.MustCompile(`\B!(\d+)\b`)},
+}
+
+// jiraRE is used for jira references configured without a pattern
+var jiraRE = regexp.MustCompile(`\b([A-Z][A-Z0-9]+-\d+)\b`)
+
+// Reference is a single reference found in a changelog entry
+type Reference struct {
+	Kind ReferenceKind
+	// Text is the reference as written, e.g. "#123"
+	Text string
+	ID   string
+	URL  string
+}
+
+// ReferencePatternsFromConfig compiles the configured patterns, returning the
+// defaults when none are configured
+func ReferencePatternsFromConfig(refs []config.Reference) ([]ReferencePattern, error) {
+	if len(refs) == 0 {
+		return DefaultReferencePatterns, nil
+	}
+
+	patterns := make([]ReferencePattern, 0, len(refs))
+	for _, r := range refs {
+		kind := ReferenceKind(r.Kind)
+		pattern := r.Pattern
+
+		var re *regexp.Regexp
+		switch {
+		case pattern != "":
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reference pattern %q: %w", pattern, err)
+			}
+			re = compiled
+		case kind == ReferenceIssue:
+			re = DefaultReferencePatterns[0].Regexp
+		case kind == ReferenceMergeRequest:
+			re = DefaultReferencePatterns[1].Regexp
+		case kind == ReferenceJira:
+			re = jiraRE
+		default:
+			return nil, fmt.Errorf("reference of kind %q needs a pattern", r.Kind)
+		}
+
+		if re.NumSubexp() < 1 {
+			return nil, fmt.Errorf("reference pattern %q has no capture group for the id", re.String())
+		}
+		if kind == ReferenceJira && r.URL == "" {
+			return nil, fmt.Errorf("jira reference pattern %q needs a url", re.String())
+		}
+
+		patterns = append(patterns, ReferencePattern{Kind: kind, Regexp: re, URL: r.URL})
+	}
+
+	return patterns, nil
+}
+
+// ReferenceLinker finds references and turns them into markdown links
+type ReferenceLinker struct {
+	// Project is used to build links, it may be nil when the remote is unknown
+	Project  *git.Project
+	Patterns []ReferencePattern
+}
+
+// NewReferenceLinker returns a linker for the project using the given patterns
+func NewReferenceLinker(project *git.Project, patterns []ReferencePattern) *ReferenceLinker {
+	if patterns == nil {
+		patterns = DefaultReferencePatterns
+	}
+	return &ReferenceLinker{Project: project, Patterns: patterns}
+}
+
+// Find returns the distinct references in text, in pattern order
+func (l *ReferenceLinker) Find(text string) []Reference {
+	var refs []Reference
+	seen := map[string]bool{}
+
+	for _, p := range l.Patterns {
+		for _, m := range p.Regexp.FindAllStringSubmatch(text, -1) {
+			if seen[m[0]] {
+				continue
+			}
+			seen[m[0]] = true
+			refs = append(refs, Reference{
+				Kind: p.Kind,
+				Text: m[0],
+				ID:   m[1],
+				URL:  l.url(p, m[1]),
+			})
+		}
+	}
+
+	return refs
+}
+
+// Linkify replaces references in text with markdown links. References that
+// cannot be linked are left untouched.
+func (l *ReferenceLinker) Linkify(text string) string {
+	type span struct {
+		start, end int
+		url        string
+	}
+
+	var spans []span
+	for _, p := range l.Patterns {
+		for _, m := range p.Regexp.FindAllStringSubmatchIndex(text, -1) {
+			u := l.url(p, text[m[2]:m[3]])
+			if u == "" {
+				continue
+			}
+			spans = append(spans, span{m[0], m[1], u})
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+
+	slices.SortStableFunc(spans, func(a, b span) int {
+		return a.start - b.start
+	})
+
+	var sb strings.Builder
+	last := 0
+	for _, s := range spans {
+		// skip matches overlapping an earlier pattern
+		if s.start < last {
+			continue
+		}
+		sb.WriteString(text[last:s.start])
+		fmt.Fprintf(&sb, "[%s](%s)", text[s.start:s.end], s.url)
+		last = s.end
+	}
+	sb.WriteString(text[last:])
+
+	return sb.String()
+}
+
+func (l *ReferenceLinker) url(p ReferencePattern, id string) string {
+	if p.URL != "" {
+		return strings.ReplaceAll(p.URL, "{id}", id)
+	}
+	if l.Project == nil {
+		return ""
+	}
+
+	switch p.Kind {
+	case ReferenceIssue:
+		return l.Project.IssueURL(id)
+	case ReferenceMergeRequest:
+		return l.Project.MergeRequestURL(id)
+	}
+	return ""
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/nick-ccc/CLIborg/internal/git"
+	"github.com/nick-ccc/CLIborg/internal/version"
+)
+
+// ErrNothingToRelease indicates that no commits warrant a new version
+var ErrNothingToRelease = errors.New("no changes to release")
+
+// ReleaseOptions controls how the next release is planned
+type ReleaseOptions struct {
+	// Bump forces the version increment, BumpNone derives it from the commits
+	Bump version.Bump
+	// Pre is the pre-release channel, e.g. "rc", empty for a final release
+	Pre string
+	// Cumulative pre-release changelogs list every change since the last
+	// final release instead of the changes since the previous pre-release
+	Cumulative bool
+	Generate   GenerateOptions
+	// Review is called with the planned release before anything is written
+	// and returns the entries to keep. Entries marked breaking by the review
+	// raise the version bump unless Bump is forced.
+	Review func(plan *ReleasePlan) ([]Entry, error)
+}
+
+// ReleasePlan describes the next release of a package
+type ReleasePlan struct {
+	Package Package
+	// PreviousTag is the release the changelog compares against, empty when
+	// the package has never been released
+	PreviousTag     string
+	PreviousVersion version.Version
+	Bump            version.Bump
+	Next            version.Version
+	Tag             string
+	ChangelogPath   string
+	Changelog       *Changelog
+	// Prereleases of Next that are consolidated by a final release
+	Prereleases []string
+}
+
+// LatestRelease returns the highest released version of the package among
+// the tags, ignoring pre-releases
+func LatestRelease(pkg Package, tags []string) (version.Version, string, bool) {
+	var (
+		latest    version.Version
+		latestTag string
+		found     bool
+	)
+	for _, tag := range tags {
+		v, ok := pkg.ParseTag(tag)
+		if !ok || v.IsPrerelease() {
+			continue
+		}
+		if !found || version.Compare(v, latest) > 0 {
+			latest, latestTag, found = v, tag, true
+		}
+	}
+	return latest, latestTag, found
+}
+
+// Prereleases returns the pre-release versions of base among the tags, in
+// ascending order
+func Prereleases(pkg Package, tags []string, base version.Version) []version.Version {
+	var pres []version.Version
+	for _, tag := range tags {
+		v, ok := pkg.ParseTag(tag)
+		if !ok || !v.IsPrerelease() || version.Compare(v.Core(), base.Core()) != 0 {
+			continue
+		}
+		pres = append(pres, v)
+	}
+	version.Sort(pres)
+	return pres
+}
+
+// BumpForEntries returns the smallest bump covering the entries: breaking
+// changes bump the major version, additions the minor and anything else the
+// patch version
+func BumpForEntries(entries []Entry) version.Bump {
+	bump := version.BumpNone
+	for _, e := range entries {
+		switch {
+		case e.Breaking:
+			return version.BumpMajor
+		case e.Section == SectionAdded:
+			bump = max(bump, version.BumpMinor)
+		default:
+			bump = max(bump, version.BumpPatch)
+		}
+	}
+	return bump
+}
+
+// PlanRelease works out the next version of a package from the commits since
+// its latest final release. Pre-releases count up from the highest existing
+// pre-release of the same version and channel, and a final release
+// consolidates the changelogs of its pre-releases.
+func PlanRelease(pkg Package, opts ReleaseOptions) (*ReleasePlan, error) {
+	if opts.Pre != "" {
+		if err := version.ValidateChannel(opts.Pre); err != nil {
+			return nil, err
+		}
+	}
+
+	tags, err := git.ListTags()
+	if err != nil {
+		return nil, err
+	}
+
+	previous, previousTag, _ := LatestRelease(pkg, tags)
+
+	// every change since the last final release decides the next version
+	gen := opts.Generate
+	gen.From = previousTag
+	gen.Package = &pkg
+	gen.FragmentDir = pkg.FragmentDir()
+	changelog, err := GenerateChangelog(gen)
+	if err != nil {
+		return nil, err
+	}
+
+	bump := opts.Bump
+	if bump == version.BumpNone {
+		bump = BumpForEntries(changelog.Entries)
+	}
+	if bump == version.BumpNone {
+		if previousTag == "" {
+			return nil, fmt.Errorf("%w for %s", ErrNothingToRelease, pkg.displayName())
+		}
+		return nil, fmt.Errorf("%w for %s since %s", ErrNothingToRelease, pkg.displayName(), previousTag)
+	}
+
+	next := previous.Bump(bump)
+	pres := Prereleases(pkg, tags, next)
+	compareFrom := previousTag
+
+	plan := &ReleasePlan{
+		Package:         pkg,
+		PreviousTag:     previousTag,
+		PreviousVersion: previous,
+		Bump:            bump,
+	}
+
+	if opts.Pre != "" {
+		n := 0
+		for _, v := range pres {
+			if num, ok := v.PrereleaseNumber(opts.Pre); ok {
+				n = max(n, num)
+			}
+		}
+		next = next.WithPrerelease(opts.Pre, n+1)
+
+		if len(pres) > 0 {
+			latestPre := pkg.Tag(pres[len(pres)-1])
+			compareFrom = latestPre
+			if !opts.Cumulative {
+				gen.From = latestPre
+				changelog, err = GenerateChangelog(gen)
+				if err != nil {
+					return nil, err
+				}
+				if len(changelog.Entries) == 0 {
+					return nil, fmt.Errorf("%w for %s since %s", ErrNothingToRelease, pkg.displayName(), latestPre)
+				}
+			}
+		}
+	} else if len(pres) > 0 {
+		changelog.Entries, err = consolidatePrereleases(pkg, pres, gen)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range pres {
+			plan.Prereleases = append(plan.Prereleases, pkg.Tag(v))
+		}
+	}
+
+	tag := pkg.Tag(next)
+
+	// the changelog was generated before the version was known
+	changelog.Version = tag
+	changelog.PreviousVersion = compareFrom
+	changelog.CompareURL = ""
+	if project, err := git.RemoteProject(remoteOrDefault(gen.Remote)); err == nil {
+		changelog.CompareURL = CompareLink(project, compareFrom, tag)
+	}
+
+	plan.Next = next
+	plan.Tag = tag
+	plan.ChangelogPath = pkg.ChangelogPath(next)
+	plan.Changelog = changelog
+
+	if opts.Review == nil {
+		return plan, nil
+	}
+	return reviewPlan(plan, opts)
+}
+
+// reviewPlan applies the review to the plan, planning again when the reviewed
+// entries need a bigger version bump
+func reviewPlan(plan *ReleasePlan, opts ReleaseOptions) (*ReleasePlan, error) {
+	entries, err := opts.Review(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	if needed := BumpForEntries(entries); opts.Bump == version.BumpNone && needed > plan.Bump {
+		opts.Bump = needed
+		opts.Review = nil
+		plan, err = PlanRelease(plan.Package, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan.Changelog.Entries = entries
+	plan.Changelog.AttachReferences()
+
+	return plan, nil
+}
+
+// consolidatePrereleases merges the changelog files of every pre-release with
+// the changes made since the latest one. Entries edited by hand in the
+// pre-release files are kept as written. When a pre-release file is missing
+// the entries are generated from every commit since the last final release.
+func consolidatePrereleases(pkg Package, pres []version.Version, gen GenerateOptions) ([]Entry, error) {
+	var lists [][]Entry
+	for _, v := range pres {
+		c, err := ReadChangelog(pkg.ChangelogPath(v))
+		if errors.Is(err, os.ErrNotExist) {
+			full, err := GenerateChangelog(gen)
+			if err != nil {
+				return nil, err
+			}
+			return full.Entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, c.Entries)
+	}
+
+	gen.From = pkg.Tag(pres[len(pres)-1])
+	since, err := GenerateChangelog(gen)
+	if err != nil {
+		return nil, err
+	}
+	lists = append(lists, since.Entries)
+
+	return MergeEntries(lists...), nil
+}
+
+// PlanReleases plans the next release of every package with changes,
+// packages without changes are left out
+func PlanReleases(pkgs []Package, opts ReleaseOptions) ([]*ReleasePlan, error) {
+	var plans []*ReleasePlan
+	for _, pkg := range pkgs {
+		plan, err := PlanRelease(pkg, opts)
+		if err != nil {
+			if errors.Is(err, ErrNothingToRelease) {
+				continue
+			}
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// WriteChangelog writes the planned changelog file
+func (p *ReleasePlan) WriteChangelog() error {
+	return WriteChangelog(p.ChangelogPath, p.Changelog)
+}
+
+func (p Package) displayName() string {
+	if p.Name == "" {
+		return "repository"
+	}
+	return p.Name
+}
+
+func remoteOrDefault(remote string) string {
+	if remote == "" {
+		return git.BaseRemoteName()
+	}
+	return remote
+}
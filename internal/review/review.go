@@ -0,0 +1,241 @@
+// Package review provides a keyboard driven terminal list for editing
+// changelog entries before they are written.
+package review
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/nick-ccc/CLIborg/internal/repository"
+)
+
+// ErrAborted indicates that the user quit the review without accepting
+var ErrAborted = errors.New("review aborted")
+
+// ANSI escape sequences used to draw the list
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	reverse     = "\x1b[7m"
+	dim         = "\x1b[2m"
+	strike      = "\x1b[9m"
+	bold        = "\x1b[1m"
+	reset       = "\x1b[0m"
+)
+
+const help = "↑/↓ move  ←/→ section  e edit  d drop  b breaking  enter accept  q quit"
+
+// key is a decoded key press
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyQuit
+	keyEdit
+	keyDrop
+	keyBreaking
+)
+
+// IsTerminal reports whether the file is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// item is an entry being reviewed
+type item struct {
+	entry   repository.Entry
+	dropped bool
+}
+
+type model struct {
+	title  string
+	items  []item
+	cursor int
+}
+
+// Run shows the entries in a list the user can edit and returns the entries
+// that were kept. Both in and out must be terminals.
+func Run(in, out *os.File, title string, entries []repository.Entry) ([]repository.Entry, error) {
+	if !IsTerminal(in) || !IsTerminal(out) {
+		return nil, errors.New("interactive review needs a terminal")
+	}
+
+	m := &model{title: title}
+	for _, e := range entries {
+		m.items = append(m.items, item{entry: e})
+	}
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("could not enter raw mode: %w", err)
+	}
+	defer term.Restore(int(in.Fd()), state)
+
+	for {
+		m.draw(out)
+
+		k, err := readKey(in)
+		if err != nil {
+			return nil, err
+		}
+
+		switch k {
+		case keyUp:
+			m.cursor = max(m.cursor-1, 0)
+		case keyDown:
+			m.cursor = min(m.cursor+1, max(len(m.items)-1, 0))
+		case keyLeft:
+			m.cycleSection(-1)
+		case keyRight:
+			m.cycleSection(1)
+		case keyDrop:
+			if len(m.items) > 0 {
+				m.items[m.cursor].dropped = !m.items[m.cursor].dropped
+			}
+		case keyBreaking:
+			if len(m.items) > 0 {
+				m.items[m.cursor].entry.Breaking = !m.items[m.cursor].entry.Breaking
+			}
+		case keyEdit:
+			if len(m.items) == 0 {
+				continue
+			}
+			// line editing needs the terminal back in cooked mode
+			term.Restore(int(in.Fd()), state)
+			m.edit(in, out)
+			if _, err := term.MakeRaw(int(in.Fd())); err != nil {
+				return nil, fmt.Errorf("could not enter raw mode: %w", err)
+			}
+		case keyEnter:
+			fmt.Fprint(out, clearScreen)
+			return m.kept(), nil
+		case keyQuit:
+			fmt.Fprint(out, clearScreen)
+			return nil, ErrAborted
+		}
+	}
+}
+
+// kept returns the entries that were not dropped
+func (m *model) kept() []repository.Entry {
+	var entries []repository.Entry
+	for _, it := range m.items {
+		if !it.dropped {
+			entries = append(entries, it.entry)
+		}
+	}
+	return entries
+}
+
+func (m *model) cycleSection(step int) {
+	if len(m.items) == 0 {
+		return
+	}
+	e := &m.items[m.cursor].entry
+	i := slices.Index(repository.Sections, e.Section)
+	n := len(repository.Sections)
+	e.Section = repository.Sections[((i+step)%n+n)%n]
+}
+
+// edit prompts for a new description of the selected entry
+func (m *model) edit(in, out *os.File) {
+	e := &m.items[m.cursor].entry
+
+	fmt.Fprintf(out, "\r\nCurrent: %s\r\nNew description (empty keeps it): ", e.Description)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil {
+		return
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		e.Description = line
+	}
+}
+
+func (m *model) draw(out *os.File) {
+	var sb strings.Builder
+	sb.WriteString(clearScreen)
+	fmt.Fprintf(&sb, "%s%s%s\r\n%s%s%s\r\n\r\n", bold, m.title, reset, dim, help, reset)
+
+	if len(m.items) == 0 {
+		sb.WriteString("No entries, press enter to continue\r\n")
+	}
+
+	for i, it := range m.items {
+		line := fmt.Sprintf("%-10s %s", it.entry.Section, it.entry.Description)
+		if it.entry.Scope != "" {
+			line = fmt.Sprintf("%-10s %s: %s", it.entry.Section, it.entry.Scope, it.entry.Description)
+		}
+		if it.entry.Breaking {
+			line += " [BREAKING]"
+		}
+
+		style := ""
+		if it.dropped {
+			style += dim + strike
+		}
+		if i == m.cursor {
+			style += reverse
+		}
+		fmt.Fprintf(&sb, "%s%s%s\r\n", style, line, reset)
+	}
+
+	fmt.Fprint(out, sb.String())
+}
+
+// readKey reads a single key press from a terminal in raw mode
+func readKey(in *os.File) (key, error) {
+	buf := make([]byte, 8)
+	n, err := in.Read(buf)
+	if err != nil {
+		return keyNone, err
+	}
+	b := buf[:n]
+
+	// arrow keys arrive as "ESC [ A" .. "ESC [ D"
+	if len(b) >= 3 && b[0] == 0x1b && (b[1] == '[' || b[1] == 'O') {
+		switch b[2] {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		}
+		return keyNone, nil
+	}
+
+	switch b[0] {
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case 'h':
+		return keyLeft, nil
+	case 'l', 's':
+		return keyRight, nil
+	case 'e':
+		return keyEdit, nil
+	case 'd', 'x':
+		return keyDrop, nil
+	case 'b', '!':
+		return keyBreaking, nil
+	case '\r', '\n':
+		return keyEnter, nil
+	case 'q', 0x1b, 0x03:
+		// q, escape and ctrl-c
+		return keyQuit, nil
+	}
+	return keyNone, nil
+}
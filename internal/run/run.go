@@ -2,13 +2,20 @@ package run
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"log/slog"
 	"os/exec"
-	"strconv"
 	"strings"
+	"time"
+
+	"github.com/nick-ccc/CLIborg/internal/logging"
 )
 
+// maxLoggedStderr is how much of a command's stderr is logged
+const maxLoggedStderr = 2048
+
 // Runnable is typically an exec.Cmd or its stub in tests
 type Runnable interface {
 	Output() ([]byte, error)
@@ -36,15 +43,17 @@ type cmdWithStderr struct {
 }
 
 func (c cmdWithStderr) Output() ([]byte, error) {
-	if debugMode, err := strconv.ParseBool(os.Getenv("DEBUG")); err == nil && debugMode {
-		fmt.Fprintf(os.Stderr, "%v\n", c.Cmd.Args)
-	}
 	if c.Cmd.Stderr != nil {
-		return c.Cmd.Output()
+		start := time.Now()
+		out, err := c.Cmd.Output()
+		logCmd(c.Cmd, start, err, nil)
+		return out, err
 	}
 	errStream := &bytes.Buffer{}
 	c.Cmd.Stderr = errStream
+	start := time.Now()
 	out, err := c.Cmd.Output()
+	logCmd(c.Cmd, start, err, errStream)
 	if err != nil {
 		err = &CmdError{errStream, c.Cmd.Args, err}
 	}
@@ -52,21 +61,81 @@ func (c cmdWithStderr) Output() ([]byte, error) {
 }
 
 func (c cmdWithStderr) Run() error {
-	if debugMode, err := strconv.ParseBool(os.Getenv("DEBUG")); err == nil && debugMode {
-		fmt.Fprintf(os.Stderr, "%v\n", c.Cmd.Args)
-	}
 	if c.Cmd.Stderr != nil {
-		return c.Cmd.Run()
+		start := time.Now()
+		err := c.Cmd.Run()
+		logCmd(c.Cmd, start, err, nil)
+		return err
 	}
 	errStream := &bytes.Buffer{}
 	c.Cmd.Stderr = errStream
+	start := time.Now()
 	err := c.Cmd.Run()
+	logCmd(c.Cmd, start, err, errStream)
 	if err != nil {
 		err = &CmdError{errStream, c.Cmd.Args, err}
 	}
 	return err
 }
 
+// logCmd logs a finished command with its duration and exit code at debug
+// level. The stderr of a failed command is included, the stderr of a
+// successful one is only logged at trace level. stderr is nil when the caller
+// streams it elsewhere.
+func logCmd(cmd *exec.Cmd, start time.Time, err error, stderr *bytes.Buffer) {
+	ctx := context.Background()
+	logger := slog.Default()
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.Any("args", cmd.Args),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("exit_code", exitCode(err)),
+	}
+	if cmd.Dir != "" {
+		attrs = append(attrs, slog.String("dir", cmd.Dir))
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		if stderr != nil && stderr.Len() > 0 {
+			attrs = append(attrs, slog.String("stderr", truncate(stderr.String())))
+		}
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, "exec", attrs...)
+
+	if err == nil && stderr != nil && stderr.Len() > 0 {
+		logger.LogAttrs(ctx, logging.LevelTrace, "exec stderr",
+			slog.Any("args", cmd.Args),
+			slog.String("stderr", truncate(stderr.String())))
+	}
+}
+
+// exitCode returns the exit code of a finished command, -1 when it could not
+// be started
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func truncate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxLoggedStderr {
+		return s
+	}
+	return s[:maxLoggedStderr] + "..."
+}
+
 // CmdError provides more visibility into why an exec.Cmd had failed
 type CmdError struct {
 	Stderr *bytes.Buffer
@@ -81,3 +150,9 @@ func (e CmdError) Error() string {
 	}
 	return fmt.Sprintf("%s%s: %s", msg, e.Args[0], e.Err)
 }
+
+// Unwrap returns the error of the command, e.g. an *exec.ExitError with its
+// exit code
+func (e CmdError) Unwrap() error {
+	return e.Err
+}
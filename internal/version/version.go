@@ -0,0 +1,206 @@
+// Package version parses, compares and bumps semantic versions (https://semver.org).
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var semverRE = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// channelRE matches a single pre-release identifier, the channel of
+// WithPrerelease
+var channelRE = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// Version is a parsed semantic version
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Pre is the pre-release part without the leading "-", e.g. "rc.1"
+	Pre string
+	// Build is the build metadata without the leading "+"
+	Build string
+}
+
+// Bump is the part of a version to increment
+type Bump int
+
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b Bump) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	}
+	return "none"
+}
+
+// Parse reads a version with an optional leading "v"
+func Parse(s string) (Version, error) {
+	m := semverRE.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid semantic version: %q", s)
+	}
+
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	patch, _ := strconv.Atoi(m[3])
+
+	return Version{
+		Major: major,
+		Minor: minor,
+		Patch: patch,
+		Pre:   m[4],
+		Build: m[5],
+	}, nil
+}
+
+// String formats the version with a leading "v"
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPrerelease reports whether the version has a pre-release part
+func (v Version) IsPrerelease() bool {
+	return v.Pre != ""
+}
+
+// Core returns the version without pre-release and build parts
+func (v Version) Core() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// ValidateChannel checks that a pre-release channel is a single identifier,
+// so "<channel>.<n>" is a valid pre-release, e.g. "rc" but not "release candidate"
+func ValidateChannel(channel string) error {
+	if !channelRE.MatchString(channel) {
+		return fmt.Errorf("invalid pre-release channel %q, use letters, digits and hyphens only", channel)
+	}
+	return nil
+}
+
+// WithPrerelease returns the version as the n-th pre-release of a channel,
+// e.g. "rc" and 2 give v1.3.0-rc.2
+func (v Version) WithPrerelease(channel string, n int) Version {
+	next := v.Core()
+	next.Pre = fmt.Sprintf("%s.%d", channel, n)
+	return next
+}
+
+// PrereleaseNumber returns n for a "<channel>.<n>" pre-release
+func (v Version) PrereleaseNumber(channel string) (int, bool) {
+	n, ok := strings.CutPrefix(v.Pre, channel+".")
+	if !ok {
+		return 0, false
+	}
+	num, err := strconv.Atoi(n)
+	if err != nil {
+		return 0, false
+	}
+	return num, true
+}
+
+// Bump returns the next version, dropping any pre-release and build parts
+func (v Version) Bump(b Bump) Version {
+	next := v.Core()
+	switch b {
+	case BumpMajor:
+		next.Major++
+		next.Minor = 0
+		next.Patch = 0
+	case BumpMinor:
+		next.Minor++
+		next.Patch = 0
+	case BumpPatch:
+		next.Patch++
+	}
+	return next
+}
+
+// Compare returns -1, 0 or 1 following semver precedence rules.
+// Build metadata is ignored.
+func Compare(a, b Version) int {
+	if c := compareInt(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	return comparePre(a.Pre, b.Pre)
+}
+
+// Sort orders versions from lowest to highest
+func Sort(versions []Version) {
+	slices.SortStableFunc(versions, Compare)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePre compares pre-release identifiers, a version without a
+// pre-release has higher precedence than one with
+func comparePre(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareInt(an, bn)
+		case aErr == nil:
+			// numeric identifiers sort before alphanumeric ones
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return compareInt(len(as), len(bs))
+}
@@ -0,0 +1,30 @@
+package version
+
+import "testing"
+
+func TestValidateChannel(t *testing.T) {
+	tests := []struct {
+		channel string
+		valid   bool
+	}{
+		{"rc", true},
+		{"beta-2", true},
+		{"alpha1", true},
+		{"", false},
+		{"release candidate", false},
+		{"rc.1", false},
+		{"rc.", false},
+		{"rc+build", false},
+	}
+	for _, tt := range tests {
+		err := ValidateChannel(tt.channel)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateChannel(%q) = %v, want valid %v", tt.channel, err, tt.valid)
+		}
+		if err == nil {
+			if _, perr := Parse(Version{Major: 1}.WithPrerelease(tt.channel, 1).String()); perr != nil {
+				t.Errorf("pre-release of channel %q does not parse: %v", tt.channel, perr)
+			}
+		}
+	}
+}